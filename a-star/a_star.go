@@ -7,8 +7,9 @@ import (
 )
 
 func AStar(startPosition game.Position, goalPosition game.Position, bodyPositionSet map[game.Position]bool, positionMatrix [][]game.Position) []main.node {
+	costs := portalCosts(goalPosition)
 	openSet := make(main.PriorityQueue, 1)
-	openSet[0] = &main.PriorityNode{startPosition, 0 + distance(startPosition, goalPosition), 0}
+	openSet[0] = &main.PriorityNode{startPosition, 0 + heuristic(startPosition, goalPosition, costs), 0}
 	heap.Init(&openSet)
 
 	cameFrom := make(map[game.Position]game.Position)
@@ -27,7 +28,7 @@ func AStar(startPosition game.Position, goalPosition game.Position, bodyPosition
 			if tentativeGScore < getScore(gScore, neighbour) {
 				cameFrom[neighbour] = current.position
 				gScore[neighbour] = tentativeGScore
-				fScore := gScore[neighbour] + distance(neighbour, goalPosition)
+				fScore := gScore[neighbour] + heuristic(neighbour, goalPosition, costs)
 
 				if priorityNode, exist := openSet.Exist(neighbour); exist {
 					openSet.update(priorityNode, priorityNode.position, fScore)
@@ -51,33 +52,17 @@ func getScore(gScore map[game.Position]int, position game.Position) int {
 	return math.MaxInt32
 }
 
+//Returns the positions reachable in one move, where a move into a portal ends on its partner.
 func getNeighbours(currentPosition game.Position, bodyPositionSet map[game.Position]bool, positionMatrix [][]game.Position) []game.Position {
-	positionCol := currentPosition.x0 / main.deltaX
-	positionRow := currentPosition.y0 / main.deltaY
-
 	var neighbours []game.Position
-	if positionCol < len(positionMatrix)-1 {
-		neighbour := positionMatrix[positionCol+1][positionRow]
-		if !bodyPositionSet[neighbour] {
-			neighbours = append(neighbours, neighbour)
-		}
-	}
-	if positionCol > 0 {
-		neighbour := positionMatrix[positionCol-1][positionRow]
-		if !bodyPositionSet[neighbour] {
-			neighbours = append(neighbours, neighbour)
+	for _, direction := range []game.Direction{game.Directions.Right, game.Directions.Left, game.Directions.Down, game.Directions.Up} {
+		next := game.Step(currentPosition, direction)
+		col, row := next.X0/game.DeltaX, next.Y0/game.DeltaY
+		if col < 0 || col >= len(positionMatrix) || row < 0 || row >= len(positionMatrix[0]) {
+			continue
 		}
-	}
-	if positionRow < len(positionMatrix[0])-1 {
-		neighbour := positionMatrix[positionCol][positionRow+1]
-		if !bodyPositionSet[neighbour] {
-			neighbours = append(neighbours, neighbour)
-		}
-	}
-	if positionRow > 0 {
-		neighbour := positionMatrix[positionCol][positionRow-1]
-		if !bodyPositionSet[neighbour] {
-			neighbours = append(neighbours, neighbour)
+		if !bodyPositionSet[next] {
+			neighbours = append(neighbours, next)
 		}
 	}
 	return neighbours
@@ -86,7 +71,11 @@ func getNeighbours(currentPosition game.Position, bodyPositionSet map[game.Posit
 func reconstructPath(cameFrom map[game.Position]game.Position, current game.Position) []main.node {
 	totalPath := []main.node{{position: current}}
 	for position, exist := cameFrom[current]; exist; {
-		totalPath = append(totalPath, main.node{getDirection(position, totalPath[len(totalPath)-1].position), position})
+		direction, throughPortal := getPortalDirection(position, totalPath[len(totalPath)-1].Position)
+		if !throughPortal {
+			direction = getDirection(position, totalPath[len(totalPath)-1].Position)
+		}
+		totalPath = append(totalPath, main.node{direction, position})
 		position, exist = cameFrom[position]
 	}
 	reverseArray(totalPath)
@@ -109,6 +98,17 @@ func getDirection(currentDirection game.Position, nextDirection game.Position) m
 	return main.directions.up
 }
 
+//Finds the direction of a move between two positions that are not adjacent, which can only be through a portal
+//or, in wrap mode, across the edge of the board.
+func getPortalDirection(currentPosition game.Position, nextPosition game.Position) (game.Direction, bool) {
+	for _, direction := range []game.Direction{game.Directions.Up, game.Directions.Right, game.Directions.Down, game.Directions.Left} {
+		if game.Step(currentPosition, direction) == nextPosition && (game.IsPortal(nextPosition) || game.Wrap) {
+			return direction, true
+		}
+	}
+	return 0, false
+}
+
 func reverseArray(positions []main.node) {
 	for i, j := 0, len(positions)-1; i < j; i, j = i+1, j-1 {
 		positions[i], positions[j] = positions[j], positions[i]
@@ -119,5 +119,50 @@ func distance(position1 game.Position, position2 game.Position) int {
 	position1Col, position1Row := position1.x0/main.deltaX, position1.y0/main.deltaY
 	position2Col, position2Row := position2.x0/main.deltaX, position2.y0/main.deltaY
 
-	return int(math.Abs(float64(position1Col-position2Col)) + math.Abs(float64(position1Row-position2Row)))
+	colDistance, rowDistance := int(math.Abs(float64(position1Col-position2Col))), int(math.Abs(float64(position1Row-position2Row)))
+	//In wrap mode the way across the edge may be shorter
+	if game.Wrap {
+		cols, rows := game.BoardSize()
+		if cols-colDistance < colDistance {
+			colDistance = cols - colDistance
+		}
+		if rows-rowDistance < rowDistance {
+			rowDistance = rows - rowDistance
+		}
+	}
+	return colDistance + rowDistance
+}
+
+//Lower bound of the moves from position to goal. Portals can make the way shorter than the distance,
+//so the bound also considers stepping into any portal and going on from there.
+func heuristic(position game.Position, goal game.Position, portalCosts map[game.Position]int) int {
+	best := distance(position, goal)
+	for entry, cost := range portalCosts {
+		if viaPortal := distance(position, entry) + cost; viaPortal < best {
+			best = viaPortal
+		}
+	}
+	return best
+}
+
+//Returns for every portal the fewest moves from stepping into it to reaching goal, ignoring obstacles
+//but going through further portals where that is shorter.
+func portalCosts(goal game.Position) map[game.Position]int {
+	portals := game.Portals()
+	costs := make(map[game.Position]int)
+	for entry, exit := range portals {
+		costs[entry] = distance(exit, goal)
+	}
+	for changed := true; changed; {
+		changed = false
+		for entry, exit := range portals {
+			for next, cost := range costs {
+				if viaNext := distance(exit, next) + cost; viaNext < costs[entry] {
+					costs[entry] = viaNext
+					changed = true
+				}
+			}
+		}
+	}
+	return costs
 }
@@ -1,19 +1,60 @@
 package autopilot
 
 import (
+	"fmt"
 	"github.com/eiba/snake"
 	"github.com/eiba/snake/a-star"
 	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/game/view"
 	"github.com/eiba/snake/hamiltonian-cycle"
+	"sort"
 )
 
+type decision int
+type decisionKinds struct {
+	Path   decision
+	Cycle  decision
+	Random decision
+}
+
+type strategy int
+type strategies struct {
+	//Takes the shortest path to the best food, following the Hamiltonian cycle when there is none
+	Greedy strategy
+	//Always follows the Hamiltonian cycle, which is slow but never runs into the body
+	Cycle strategy
+}
+
+const maxFallbackPositions = 20
+
 var (
-	foodPath  []hamiltonian_cycle.node
-	pathIndex = -1
+	foodPath          []hamiltonian_cycle.node
+	pathIndex         = -1
+	Decisions         = decisionKinds{0, 1, 2}
+	LastDecision      = Decisions.Path
+	Strategies        = strategies{0, 1}
+	CurrentStrategy   = Strategies.Greedy
+	fallbackPositions []game.Position
+	autopilotStat     = view.RegisterStat("Autopilot")
+	//The food foodPath leads to
+	goal game.Position
 )
 
+//Returns the cells a path must not cross: the obstacles and the poison, which would shrink the snake.
+//The body is left out while the ghost effect lets the head pass through it.
+func pathObstacles() map[game.Position]bool {
+	obstacles := game.ObstacleSet()
+	if game.GhostActive() {
+		obstacles = game.BoardObstacleSet()
+	}
+	for position := range game.PoisonSet() {
+		obstacles[position] = true
+	}
+	return obstacles
+}
+
 func initiateAStar(goal game.position) []hamiltonian_cycle.node {
-	foodPath = a_star.AStar(game.snakeHead.position, goal, game.getsnakePositionSet(game.snakeBodyParts), main.positionMatrix)
+	foodPath = a_star.AStar(game.snakeHead.position, goal, pathObstacles(), main.positionMatrix)
 	if len(foodPath) == 0 {
 		pathIndex = -1
 		return foodPath
@@ -28,23 +69,66 @@ func getNextPositionInAStarPath() bool {
 	if pathIndex == len(foodPath)-1 {
 		return false
 	}
+	//The snake may have been moved off the path, e.g. by rewinding, so the path has to be recalculated
+	if pathIndex < 0 || foodPath[pathIndex].Position != game.snakeHead.position {
+		return false
+	}
+	//The food may have expired or been eaten on the way
+	if !goalExists(goal) {
+		return false
+	}
+	//A hazard may have moved into the way
+	if pathIndex+1 < len(foodPath) && game.HazardSet()[foodPath[pathIndex+1].Position] {
+		return false
+	}
 	game.headDirection = foodPath[pathIndex].direction
 	pathIndex++
 	return true
 }
 
+func UseStrategy(name string) error {
+	for _, s := range []strategy{Strategies.Greedy, Strategies.Cycle} {
+		if s.String() == name {
+			CurrentStrategy = s
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown autopilot strategy %q", name)
+}
+
+//Shows the strategy and how it decided the last move on the stats view, e.g. "greedy, path", or that the autopilot is off.
+func UpdateStat(enabled bool) error {
+	if !enabled {
+		return view.SetStatText(autopilotStat, "off")
+	}
+	return view.SetStatText(autopilotStat, fmt.Sprint(CurrentStrategy, ", ", LastDecision))
+}
+
 func autopilot() error {
-	if getNextPositionInAStarPath() {
-		return nil
+	LastDecision = Decisions.Path
+	var pathToFood []hamiltonian_cycle.node
+	if CurrentStrategy == Strategies.Greedy {
+		if getNextPositionInAStarPath() {
+			return nil
+		}
+		for _, position := range rankGoals(game.FoodGoals()) {
+			goal = position
+			if pathToFood = initiateAStar(position); len(pathToFood) > 0 {
+				break
+			}
+		}
+	} else {
+		pathIndex = -1
 	}
-	pathToFood := initiateAStar(game.foodView.position)
 	if len(pathToFood) == 0 {
+		LastDecision = Decisions.Cycle
 		headPosition := game.snakeHead.position
-		headCycleIndex := hamiltonian_cycle.cycleIndexMap[headPosition]
-		headCycleNode := hamiltonian_cycle.hCycle[headCycleIndex]
-
-		if headCycleNode.direction != game.getOppositeDirection(game.snakeHead.currentDirection) {
-			game.headDirection = headCycleNode.direction
+		//Without a cycle through the head, e.g. on a level whose floor has none, the snake goes on until it has to turn
+		if headCycleIndex, exist := hamiltonian_cycle.cycleIndexMap[headPosition]; exist {
+			headCycleNode := hamiltonian_cycle.hCycle[headCycleIndex]
+			if headCycleNode.direction != game.getOppositeDirection(game.snakeHead.currentDirection) {
+				game.headDirection = headCycleNode.direction
+			}
 		}
 
 		for i := 1; i < 100; i++ {
@@ -52,19 +136,70 @@ func autopilot() error {
 				break
 			}
 			game.headDirection = getRandomValidDirection(game.snakeHead.currentDirection)
+			LastDecision = Decisions.Random
+		}
+		if LastDecision == Decisions.Random {
+			addFallbackPosition(game.snakeHead.position)
 		}
 	}
 	return nil
 }
 
+//Orders the food by value per tick it takes to reach it, leaving out food that disappears before it can be reached.
+func rankGoals(goals []game.FoodGoal) []game.Position {
+	type rankedGoal struct {
+		position game.Position
+		utility  float64
+	}
+	var ranked []rankedGoal
+	for _, g := range goals {
+		distance := cellDistance(game.snakeHead.position, g.Position)
+		if g.TicksLeft >= 0 && g.TicksLeft < distance {
+			continue
+		}
+		ranked = append(ranked, rankedGoal{g.Position, g.Value / float64(distance+1)})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].utility > ranked[j].utility
+	})
+	positions := make([]game.Position, len(ranked))
+	for i, g := range ranked {
+		positions[i] = g.position
+	}
+	return positions
+}
+
+func goalExists(position game.Position) bool {
+	for _, g := range game.FoodGoals() {
+		if g.Position == position {
+			return true
+		}
+	}
+	return false
+}
+
+//Manhattan distance between two positions in board cells
+func cellDistance(from game.Position, to game.Position) int {
+	dx, dy := (to.X0-from.X0)/game.DeltaX, (to.Y0-from.Y0)/game.DeltaY
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+//Checks that moving in direction neither hits the body, which follows the head's path, nor leaves the board or hits
+//a wall or a hazard. The body is not in the way while the ghost effect is active.
 func validDirection(direction game.direction) bool {
 	positions := make([]game.position, len(game.snakeBodyParts)-1)
 	for i := 1; i < len(game.snakeBodyParts); i++ {
-		positions[i-1] = game.getPositionOfNextMove(game.snakeBodyParts[i-1].currentDirection, game.snakeBodyParts[i-1].position, false)
+		positions[i-1] = game.snakeBodyParts[i-1].position
 	}
 
-	nextPosition := game.getPositionOfNextMove(direction, game.snakeHead.position, true)
-	if game.positionsOverlap(nextPosition, positions) || game.mainViewCollision(nextPosition) {
+	nextPosition := game.Step(game.snakeHead.position, direction)
+	if (!game.GhostActive() && game.positionsOverlap(nextPosition, positions)) || game.mainViewCollision(nextPosition) || game.IsWall(nextPosition) || game.IsHazard(nextPosition) {
 		return false
 	}
 	return true
@@ -79,4 +214,29 @@ func getRandomValidDirection(currentDirection game.direction) game.direction {
 			return direction
 		}
 	}
-}
\ No newline at end of file
+}
+
+//Remembers a position where the autopilot had to turn at random, so the overlay can mark it.
+func addFallbackPosition(position game.Position) {
+	fallbackPositions = append(fallbackPositions, position)
+	if len(fallbackPositions) > maxFallbackPositions {
+		fallbackPositions = fallbackPositions[1:]
+	}
+}
+
+func (s strategy) String() string {
+	if s == Strategies.Cycle {
+		return "cycle"
+	}
+	return "greedy"
+}
+
+func (d decision) String() string {
+	switch d {
+	case Decisions.Cycle:
+		return "cycle"
+	case Decisions.Random:
+		return "random"
+	}
+	return "path"
+}
@@ -0,0 +1,72 @@
+package autopilot
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/game/view"
+	"github.com/eiba/snake/hamiltonian-cycle"
+)
+
+var (
+	OverlayEnabled = false
+	cycleColors    = []gocui.Attribute{gocui.ColorBlue, gocui.ColorCyan, gocui.ColorGreen, gocui.ColorYellow, gocui.ColorMagenta, gocui.ColorRed}
+	directionRunes = map[game.Direction]rune{
+		game.Directions.Up:    '↑',
+		game.Directions.Right: '→',
+		game.Directions.Down:  '↓',
+		game.Directions.Left:  '←',
+	}
+)
+
+func InitOverlayKey(gui *gocui.Gui) error {
+	if err := game.SetActionKeybinding(gui, game.ActionOverlay,
+		func(gui *gocui.Gui, view *gocui.View) error {
+			OverlayEnabled = !OverlayEnabled
+			return nil
+		}); err != nil {
+		return err
+	}
+	return nil
+}
+
+//Draws the autopilot's plan below the snake: the Hamiltonian cycle as coloured arrows,
+//the current A* path to the food and the positions where it had to fall back to a random direction.
+func DrawOverlay(board *view.Board) {
+	if !OverlayEnabled {
+		return
+	}
+	addCycleCells(board)
+	addPathCells(board)
+	for _, position := range fallbackPositions {
+		board.Set(position, view.CellStyle{Glyph: 'x', Fg: gocui.ColorRed, Bg: gocui.ColorDefault})
+	}
+}
+
+func addCycleCells(board *view.Board) {
+	cycleLength := hamiltonian_cycle.CycleLength()
+	if cycleLength == 0 {
+		return
+	}
+	for _, positions := range board.PositionMatrix() {
+		for _, position := range positions {
+			direction, index, exist := hamiltonian_cycle.CycleNode(position)
+			if !exist {
+				continue
+			}
+			color := cycleColors[index*len(cycleColors)/cycleLength]
+			board.Set(position, view.CellStyle{Glyph: directionRunes[direction], Fg: color, Bg: gocui.ColorDefault})
+		}
+	}
+}
+
+func addPathCells(board *view.Board) {
+	if pathIndex < 0 {
+		return
+	}
+	for i := pathIndex; i < len(foodPath); i++ {
+		board.Set(foodPath[i].Position, view.CellStyle{Glyph: '•', Fg: gocui.ColorYellow, Bg: gocui.ColorDefault})
+	}
+	if pathIndex < len(foodPath) {
+		board.Set(foodPath[pathIndex].Position, view.CellStyle{Glyph: '•', Fg: gocui.ColorBlack, Bg: gocui.ColorWhite})
+	}
+}
@@ -0,0 +1,170 @@
+package campaign
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/maze"
+)
+
+var directionOffsets = map[game.Direction]game.Cell{
+	game.Directions.Up:    {Col: 0, Row: -1},
+	game.Directions.Right: {Col: 1, Row: 0},
+	game.Directions.Down:  {Col: 0, Row: 1},
+	game.Directions.Left:  {Col: -1, Row: 0},
+}
+
+//FloorCells returns every cell of the stage that is not a wall.
+func FloorCells(stage game.Stage) map[game.Cell]bool {
+	walls := make(map[game.Cell]bool)
+	for _, cell := range stage.Walls {
+		walls[cell] = true
+	}
+	floor := make(map[game.Cell]bool)
+	for col := 0; col < stage.Cols; col++ {
+		for row := 0; row < stage.Rows; row++ {
+			if cell := (game.Cell{Col: col, Row: row}); !walls[cell] {
+				floor[cell] = true
+			}
+		}
+	}
+	return floor
+}
+
+//Check looks for problems in a stage's layout: floor that cannot be reached, a start that runs straight into a wall
+//and floor the autopilot cannot follow a cycle through. Portals count as floor that leads to their partner, but are
+//left out of the cycle. An empty result means the stage is fine.
+func Check(stage game.Stage) []string {
+	floor := FloorCells(stage)
+	if len(floor) == 0 {
+		return []string{"there is no floor"}
+	}
+	var problems []string
+	if stage.HasStart && !floor[stage.Start] {
+		problems = append(problems, "the start is on a wall")
+	} else if stage.HasStart && portalPartners(stage)[stage.Start] != nil {
+		problems = append(problems, "the start is on a portal")
+	} else if stage.HasStart {
+		problems = append(problems, checkStart(stage, floor)...)
+	}
+
+	if unreachable := len(floor) - len(ReachableCells(stage)); unreachable > 0 {
+		problems = append(problems, fmt.Sprintf("%d floor cells cannot be reached", unreachable))
+	}
+
+	if cycleCells := cycleFloor(stage); len(cycleCells) == 0 {
+		problems = append(problems, "no autopilot cycle: there is no floor besides the portals")
+	} else if _, found := maze.Cycle(cycleCells); !found {
+		if reason := cycleImpossible(cycleCells); reason != "" {
+			problems = append(problems, "no autopilot cycle: "+reason)
+		} else {
+			problems = append(problems, "no autopilot cycle found, the floor cannot be split into 2x2 squares")
+		}
+	}
+	return problems
+}
+
+//Checks that the starting body fits behind the head and that the head does not face a wall.
+func checkStart(stage game.Stage, floor map[game.Cell]bool) []string {
+	var problems []string
+	offset := directionOffsets[stage.StartDirection]
+	ahead := game.Cell{Col: stage.Start.Col + offset.Col, Row: stage.Start.Row + offset.Row}
+	if !floor[ahead] {
+		problems = append(problems, "the snake starts facing a wall")
+	}
+	for i := 1; i < stage.StartLength; i++ {
+		behind := game.Cell{Col: stage.Start.Col - i*offset.Col, Row: stage.Start.Row - i*offset.Row}
+		if !floor[behind] {
+			problems = append(problems, fmt.Sprintf("the starting body of length %d does not fit behind the start", stage.StartLength))
+			break
+		}
+	}
+	return problems
+}
+
+//Returns the floor the autopilot's cycle has to go through: every floor cell but the portals, as stepping into either
+//cell of a pair moves the head to the other one.
+func cycleFloor(stage game.Stage) map[game.Cell]bool {
+	floor := FloorCells(stage)
+	for _, pair := range stage.Portals {
+		delete(floor, pair[0])
+		delete(floor, pair[1])
+	}
+	return floor
+}
+
+//Maps the cells of every portal pair to their partner.
+func portalPartners(stage game.Stage) map[game.Cell]*game.Cell {
+	partners := make(map[game.Cell]*game.Cell)
+	for _, pair := range stage.Portals {
+		pair := pair
+		partners[pair[0]] = &pair[1]
+		partners[pair[1]] = &pair[0]
+	}
+	return partners
+}
+
+//ReachableCells returns the floor cells that can be reached from the start, or from the first floor cell in reading
+//order if the stage has no start. Stepping into a portal reaches both of its cells.
+func ReachableCells(stage game.Stage) map[game.Cell]bool {
+	floor := FloorCells(stage)
+	partners := portalPartners(stage)
+	start, found := stage.Start, stage.HasStart && floor[stage.Start]
+	for row := 0; row < stage.Rows && !found; row++ {
+		for col := 0; col < stage.Cols && !found; col++ {
+			start = game.Cell{Col: col, Row: row}
+			found = floor[start]
+		}
+	}
+	if !found {
+		return map[game.Cell]bool{}
+	}
+
+	reached := map[game.Cell]bool{start: true}
+	queue := []game.Cell{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, offset := range directionOffsets {
+			next := game.Cell{Col: current.Col + offset.Col, Row: current.Row + offset.Row}
+			if !floor[next] || reached[next] {
+				continue
+			}
+			reached[next] = true
+			if partner := partners[next]; partner != nil {
+				next = *partner
+				if reached[next] {
+					continue
+				}
+				reached[next] = true
+			}
+			queue = append(queue, next)
+		}
+	}
+	return reached
+}
+
+//Returns why no Hamiltonian cycle can exist, or "" if none of the simple reasons apply. A cycle on a grid alternates
+//between the two colours of a chessboard, so both need the same number of cells, and it enters and leaves every cell.
+func cycleImpossible(floor map[game.Cell]bool) string {
+	balance := 0
+	for cell := range floor {
+		if (cell.Col+cell.Row)%2 == 0 {
+			balance++
+		} else {
+			balance--
+		}
+		neighbours := 0
+		for _, offset := range directionOffsets {
+			if floor[game.Cell{Col: cell.Col + offset.Col, Row: cell.Row + offset.Row}] {
+				neighbours++
+			}
+		}
+		if neighbours < 2 {
+			return fmt.Sprintf("dead end at column %d, row %d", cell.Col+1, cell.Row+1)
+		}
+	}
+	if balance != 0 {
+		return "the floor has more cells of one checkerboard colour than of the other"
+	}
+	return ""
+}
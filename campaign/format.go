@@ -0,0 +1,63 @@
+package campaign
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game"
+	"strings"
+)
+
+//Format writes a stage in the text format read by Parse.
+func Format(stage game.Stage) string {
+	var text strings.Builder
+	if stage.Name != "" {
+		fmt.Fprintf(&text, "name: %v\n", stage.Name)
+	}
+	fmt.Fprintf(&text, "goal: %v\n", formatGoal(stage.Goal))
+	fmt.Fprintf(&text, "speed: %v\n", stage.Speed)
+	fmt.Fprintf(&text, "length: %d\n", stage.StartLength)
+	for name, direction := range directionNames {
+		if direction == stage.StartDirection {
+			fmt.Fprintf(&text, "direction: %v\n", name)
+		}
+	}
+	fmt.Fprintln(&text, "map:")
+
+	walls := make(map[game.Cell]bool)
+	for _, cell := range stage.Walls {
+		walls[cell] = true
+	}
+	portals := make(map[game.Cell]rune)
+	for i, pair := range stage.Portals {
+		portals[pair[0]] = FirstPortalRune + rune(i)
+		portals[pair[1]] = FirstPortalRune + rune(i)
+	}
+	for row := 0; row < stage.Rows; row++ {
+		for col := 0; col < stage.Cols; col++ {
+			cell := game.Cell{Col: col, Row: row}
+			switch {
+			case stage.HasStart && cell == stage.Start:
+				text.WriteRune(startRune)
+			case walls[cell]:
+				text.WriteRune(wallRune)
+			case portals[cell] != 0:
+				text.WriteRune(portals[cell])
+			default:
+				text.WriteRune(floorRune)
+			}
+		}
+		text.WriteRune('\n')
+	}
+	return text.String()
+}
+
+func formatGoal(goal game.Goal) string {
+	switch goal.Kind {
+	case game.GoalKinds.Golden:
+		return fmt.Sprintf("golden %d", goal.Target)
+	case game.GoalKinds.Survive:
+		return fmt.Sprintf("survive %v", goal.Duration)
+	case game.GoalKinds.Endless:
+		return "endless"
+	}
+	return fmt.Sprintf("length %d", goal.Target)
+}
@@ -0,0 +1,82 @@
+package campaign
+
+import (
+	"encoding/json"
+	"github.com/eiba/snake/config"
+	"github.com/eiba/snake/game"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const progressFileName = "campaign.json"
+
+//Progress is the furthest stage that has been unlocked. It is kept in the user config directory.
+type Progress struct {
+	Unlocked int
+}
+
+//Loads the campaign progress, with only the first stage unlocked if none has been saved yet.
+func LoadProgress() (Progress, error) {
+	filePath, err := config.FilePath(progressFileName)
+	if err != nil {
+		return Progress{}, err
+	}
+	data, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return Progress{Unlocked: 1}, nil
+	}
+	if err != nil {
+		return Progress{}, err
+	}
+	var progress Progress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return Progress{}, err
+	}
+	if progress.Unlocked < 1 {
+		progress.Unlocked = 1
+	}
+	return progress, nil
+}
+
+func saveProgress(progress Progress) error {
+	filePath, err := config.FilePath(progressFileName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+//Unlocks the stage with the given number and all stages before it.
+func Unlock(number int) error {
+	progress, err := LoadProgress()
+	if err != nil {
+		return err
+	}
+	if number > len(stageTexts) {
+		number = len(stageTexts)
+	}
+	if number <= progress.Unlocked {
+		return nil
+	}
+	progress.Unlocked = number
+	return saveProgress(progress)
+}
+
+//Returns the next stage after the cleared one, or nil if it was the last, and saves the progress.
+func NextStage(cleared game.Stage) (*game.Stage, error) {
+	if err := Unlock(cleared.Number + 1); err != nil {
+		return nil, err
+	}
+	if cleared.Number >= len(stageTexts) {
+		return nil, nil
+	}
+	return Stage(cleared.Number + 1)
+}
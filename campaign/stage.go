@@ -0,0 +1,180 @@
+package campaign
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/maze"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	wallRune  = '#'
+	startRune = '@'
+	floorRune = '.'
+	//Portal pairs are marked with the letters from a to z
+	FirstPortalRune = 'a'
+	lastPortalRune  = 'z'
+	MaxPortalPairs  = lastPortalRune - FirstPortalRune + 1
+)
+
+var directionNames = map[string]game.Direction{
+	"up":    game.Directions.Up,
+	"right": game.Directions.Right,
+	"down":  game.Directions.Down,
+	"left":  game.Directions.Left,
+}
+
+//Parse reads a stage in the text format: "key: value" settings, followed by "map:" and the board,
+//one line per row, with # for walls, @ for the starting position of the head and a pair of the same lowercase letter
+//for each pair of portals. Any other character is empty.
+//
+//	name: The box
+//	goal: length 20        (or "golden 5", or "survive 2m")
+//	speed: 90ms
+//	length: 3
+//	direction: right
+//	map:
+//	##########
+//	#@..a..a.#
+//	##########
+func Parse(text string) (game.Stage, error) {
+	stage := game.Stage{StartLength: 1, StartDirection: game.Directions.Right}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if line == "map:" {
+			break
+		}
+		key, value, err := splitSetting(line)
+		if err == nil {
+			err = setSetting(&stage, key, value)
+		}
+		if err != nil {
+			return game.Stage{}, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+	}
+
+	portalEnds := make(map[rune][]game.Cell)
+	for scanner.Scan() {
+		row := []rune(strings.TrimRight(scanner.Text(), " \t"))
+		if len(row) == 0 {
+			continue
+		}
+		for col, r := range row {
+			switch r {
+			case wallRune:
+				stage.Walls = append(stage.Walls, game.Cell{Col: col, Row: stage.Rows})
+			case startRune:
+				stage.HasStart = true
+				stage.Start = game.Cell{Col: col, Row: stage.Rows}
+			default:
+				if r >= FirstPortalRune && r <= lastPortalRune {
+					portalEnds[r] = append(portalEnds[r], game.Cell{Col: col, Row: stage.Rows})
+				}
+			}
+		}
+		if len(row) > stage.Cols {
+			stage.Cols = len(row)
+		}
+		stage.Rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return game.Stage{}, err
+	}
+	for r := FirstPortalRune; r <= lastPortalRune; r++ {
+		ends, exist := portalEnds[r]
+		if !exist {
+			continue
+		}
+		if len(ends) != 2 {
+			return game.Stage{}, fmt.Errorf("portal %c must appear exactly twice, found %d", r, len(ends))
+		}
+		stage.Portals = append(stage.Portals, [2]game.Cell{ends[0], ends[1]})
+	}
+	stage.Cycle, _ = maze.Cycle(cycleFloor(stage))
+	return stage, validate(stage)
+}
+
+func splitSetting(line string) (string, string, error) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("expected \"key: value\", got %q", line)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+func setSetting(stage *game.Stage, key string, value string) error {
+	var err error
+	switch key {
+	case "name":
+		stage.Name = value
+	case "goal":
+		stage.Goal, err = parseGoal(value)
+	case "speed":
+		stage.Speed, err = time.ParseDuration(value)
+	case "length":
+		stage.StartLength, err = strconv.Atoi(value)
+	case "direction":
+		direction, exist := directionNames[value]
+		if !exist {
+			return fmt.Errorf("unknown direction %q", value)
+		}
+		stage.StartDirection = direction
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	return err
+}
+
+func parseGoal(value string) (game.Goal, error) {
+	if value == "endless" {
+		return game.Goal{Kind: game.GoalKinds.Endless}, nil
+	}
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return game.Goal{}, fmt.Errorf("expected a goal like \"length 20\", got %q", value)
+	}
+	switch fields[0] {
+	case "length", "golden":
+		target, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return game.Goal{}, err
+		}
+		kind := game.GoalKinds.Length
+		if fields[0] == "golden" {
+			kind = game.GoalKinds.Golden
+		}
+		return game.Goal{Kind: kind, Target: target}, nil
+	case "survive":
+		duration, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return game.Goal{}, err
+		}
+		return game.Goal{Kind: game.GoalKinds.Survive, Duration: duration}, nil
+	}
+	return game.Goal{}, fmt.Errorf("unknown goal %q", fields[0])
+}
+
+func validate(stage game.Stage) error {
+	if stage.Cols < 2 || stage.Rows < 2 {
+		return fmt.Errorf("map must be at least 2 by 2 cells")
+	}
+	if stage.Speed < time.Millisecond {
+		return fmt.Errorf("speed must be at least 1ms")
+	}
+	if stage.StartLength < 1 {
+		return fmt.Errorf("length must be at least 1")
+	}
+	if stage.Goal.Kind != game.GoalKinds.Endless && stage.Goal.Target < 1 && stage.Goal.Duration <= 0 {
+		return fmt.Errorf("missing goal")
+	}
+	return nil
+}
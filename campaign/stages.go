@@ -0,0 +1,131 @@
+package campaign
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game"
+)
+
+//The stages of the campaign in the order they are played
+var stageTexts = []string{
+	`name: Warm-up
+goal: length 10
+speed: 110ms
+map:
+..............................
+..............................
+..............................
+..............................
+..............................
+..............................
+..............................
+..............@...............
+..............................
+..............................
+..............................
+..............................
+..............................
+..............................
+..............................
+..............................`,
+	`name: The box
+goal: length 20
+speed: 100ms
+length: 3
+map:
+##############################
+#............................#
+#............................#
+#............................#
+#............................#
+#............................#
+#............................#
+#......@.....................#
+#............................#
+#............................#
+#............................#
+#............................#
+#............................#
+#............................#
+#............................#
+##############################`,
+	`name: Gold rush
+goal: golden 3
+speed: 90ms
+length: 3
+map:
+##############################
+#............................#
+#............................#
+#....########....########....#
+#....########....########....#
+#............................#
+#............................#
+#......@.....................#
+#............................#
+#............................#
+#............................#
+#....########....########....#
+#....########....########....#
+#............................#
+#............................#
+##############################`,
+	`name: Corridors
+goal: length 30
+speed: 80ms
+length: 4
+map:
+##############################
+#............................#
+#.....@......................#
+#######################......#
+#######################......#
+#............................#
+#............................#
+#......#######################
+#......#######################
+#............................#
+#............................#
+#######################......#
+#######################......#
+#............................#
+#............................#
+##############################`,
+	`name: Endurance
+goal: survive 2m
+speed: 70ms
+length: 5
+map:
+##############################
+#............................#
+#............................#
+#....####..........####......#
+#....####..........####......#
+#....##..............##......#
+#....##..............##......#
+#...........@................#
+#............................#
+#....##..............##......#
+#....##..............##......#
+#....####..........####......#
+#....####..........####......#
+#............................#
+#............................#
+##############################`,
+}
+
+func StageCount() int {
+	return len(stageTexts)
+}
+
+//Stage returns the campaign stage with the given number, counting from 1.
+func Stage(number int) (*game.Stage, error) {
+	if number < 1 || number > len(stageTexts) {
+		return nil, fmt.Errorf("there is no stage %d, the campaign has %d stages", number, len(stageTexts))
+	}
+	stage, err := Parse(stageTexts[number-1])
+	if err != nil {
+		return nil, fmt.Errorf("stage %d: %v", number, err)
+	}
+	stage.Number = number
+	return &stage, nil
+}
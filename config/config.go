@@ -0,0 +1,462 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	fileName  = "config.json"
+	envPrefix = "SNAKE_"
+)
+
+//The config file the configuration was loaded from, which Update writes to
+var loadedPath = ""
+
+//Duration is a time.Duration that is written as a string like "50ms" in the config file.
+type Duration struct {
+	time.Duration
+}
+
+type Keybindings struct {
+	Preset   string
+	Bindings map[string][]string
+}
+
+//Config holds every setting of the game. Settings are layered in the order:
+//defaults, config file, environment variables and command-line flags.
+type Config struct {
+	Difficulty      string
+	TickInterval    Duration
+	SpeedStep       Duration
+	SpeedFloor      Duration
+	AutoPilot       bool
+	Strategy        string
+	DeltaX          int
+	DeltaY          int
+	SidePanelWidth  int
+	InputQueueDepth int
+	CasualMode      bool
+	RewindSeconds   int
+	Theme           string
+	Renderer        string
+	BoardCols       int
+	BoardRows       int
+	FitTerminal     bool
+	HTTPAddress     string
+	HTTPControl     bool
+	RecordPath      string
+	GoldenFoodRate  float64
+	GrowthFoodRate  float64
+	PoisonFoodRate  float64
+	GoldenFoodTicks int
+	PoisonFoodTicks int
+	PowerUpRate     float64
+	PowerUpTicks    int
+	PowerUpLifetime int
+	SlowMotion      int
+	Hazards         int
+	Enemies         int
+	Bots            int
+	Campaign        bool
+	Stage           int
+	LevelPath       string
+	Maze            string
+	MazeSeed        int
+	MazeDensity     float64
+	CorridorWidth   int
+	BattleRoyale    bool
+	ShrinkInterval  int
+	ShrinkWarning   int
+	Wrap            bool
+	Menu            bool
+	Keybindings     Keybindings
+}
+
+//A setting that can be set from an environment variable and a command-line flag with the same name.
+type setting struct {
+	name  string
+	usage string
+	bind  func(config *Config) flag.Value
+}
+
+var settings = []setting{
+	{"difficulty", "speed curve: easy, normal, hard or insane", func(c *Config) flag.Value { return (*stringValue)(&c.Difficulty) }},
+	{"tick-interval", "starting time between two moves of the snake, 0 for the difficulty's", func(c *Config) flag.Value { return (*durationValue)(&c.TickInterval.Duration) }},
+	{"speed-step", "how much faster the snake gets as it grows, 0 for the difficulty's", func(c *Config) flag.Value { return (*durationValue)(&c.SpeedStep.Duration) }},
+	{"speed-floor", "shortest time between two moves, 0 for the difficulty's", func(c *Config) flag.Value { return (*durationValue)(&c.SpeedFloor.Duration) }},
+	{"autopilot", "start with the autopilot enabled", func(c *Config) flag.Value { return (*boolValue)(&c.AutoPilot) }},
+	{"strategy", "autopilot strategy: greedy or cycle", func(c *Config) flag.Value { return (*stringValue)(&c.Strategy) }},
+	{"delta-x", "width of a board cell in terminal columns", func(c *Config) flag.Value { return (*intValue)(&c.DeltaX) }},
+	{"delta-y", "height of a board cell in terminal rows", func(c *Config) flag.Value { return (*intValue)(&c.DeltaY) }},
+	{"side-panel-width", "width of the side panel in terminal columns", func(c *Config) flag.Value { return (*intValue)(&c.SidePanelWidth) }},
+	{"input-queue-depth", "number of turns that can be queued within one tick", func(c *Config) flag.Value { return (*intValue)(&c.InputQueueDepth) }},
+	{"casual", "start in casual mode", func(c *Config) flag.Value { return (*boolValue)(&c.CasualMode) }},
+	{"rewind-seconds", "seconds rewound in casual mode", func(c *Config) flag.Value { return (*intValue)(&c.RewindSeconds) }},
+	{"theme", "colour theme: dark, light, high-contrast or monochrome", func(c *Config) flag.Value { return (*stringValue)(&c.Theme) }},
+	{"renderer", "renderer: gocui, ansi or text", func(c *Config) flag.Value { return (*stringValue)(&c.Renderer) }},
+	{"board-cols", "board width in cells for the ansi and text renderers, generated boards, new levels and gocui without fit-terminal", func(c *Config) flag.Value { return (*intValue)(&c.BoardCols) }},
+	{"board-rows", "board height in cells for the ansi and text renderers, generated boards, new levels and gocui without fit-terminal", func(c *Config) flag.Value { return (*intValue)(&c.BoardRows) }},
+	{"fit-terminal", "size the gocui board to the terminal instead of board-cols by board-rows", func(c *Config) flag.Value { return (*boolValue)(&c.FitTerminal) }},
+	{"http", "address to serve the web spectator page on, e.g. :8080", func(c *Config) flag.Value { return (*stringValue)(&c.HTTPAddress) }},
+	{"http-control", "let web spectators steer the snake with the arrow keys", func(c *Config) flag.Value { return (*boolValue)(&c.HTTPControl) }},
+	{"record", "file to save a replay of the game to when it exits", func(c *Config) flag.Value { return (*stringValue)(&c.RecordPath) }},
+	{"golden-food-rate", "chance per tick that a golden food appears", func(c *Config) flag.Value { return (*floatValue)(&c.GoldenFoodRate) }},
+	{"growth-food-rate", "chance per tick that a food that grows the snake by 3 appears", func(c *Config) flag.Value { return (*floatValue)(&c.GrowthFoodRate) }},
+	{"poison-food-rate", "chance per tick that a poison that shrinks the snake appears", func(c *Config) flag.Value { return (*floatValue)(&c.PoisonFoodRate) }},
+	{"golden-food-ticks", "ticks until a golden food disappears", func(c *Config) flag.Value { return (*intValue)(&c.GoldenFoodTicks) }},
+	{"poison-food-ticks", "ticks until a poison disappears", func(c *Config) flag.Value { return (*intValue)(&c.PoisonFoodTicks) }},
+	{"power-up-rate", "chance per tick that a power-up appears", func(c *Config) flag.Value { return (*floatValue)(&c.PowerUpRate) }},
+	{"power-up-ticks", "ticks the slow-motion and ghost power-ups last", func(c *Config) flag.Value { return (*intValue)(&c.PowerUpTicks) }},
+	{"power-up-lifetime", "ticks until a power-up that is not picked up disappears", func(c *Config) flag.Value { return (*intValue)(&c.PowerUpLifetime) }},
+	{"slow-motion", "how many times slower the game runs in slow motion", func(c *Config) flag.Value { return (*intValue)(&c.SlowMotion) }},
+	{"hazards", "number of blocks that patrol the board", func(c *Config) flag.Value { return (*intValue)(&c.Hazards) }},
+	{"enemies", "number of enemy snakes that compete for the food", func(c *Config) flag.Value { return (*intValue)(&c.Enemies) }},
+	{"bots", "number of enemy snakes steered by external bots over the spectator websocket, needs -http-control", func(c *Config) flag.Value { return (*intValue)(&c.Bots) }},
+	{"campaign", "play the campaign, continuing at the furthest unlocked stage", func(c *Config) flag.Value { return (*boolValue)(&c.Campaign) }},
+	{"stage", "campaign stage to play, 0 for the furthest unlocked stage", func(c *Config) flag.Value { return (*intValue)(&c.Stage) }},
+	{"level", "level file to play, as saved by snake edit", func(c *Config) flag.Value { return (*stringValue)(&c.LevelPath) }},
+	{"maze", "play on a generated board: maze or rooms", func(c *Config) flag.Value { return (*stringValue)(&c.Maze) }},
+	{"maze-seed", "seed of the generated board, 0 for a random one", func(c *Config) flag.Value { return (*intValue)(&c.MazeSeed) }},
+	{"maze-density", "how much of the generated board is wall, from 0 to 1, -1 for the difficulty's", func(c *Config) flag.Value { return (*floatValue)(&c.MazeDensity) }},
+	{"corridor-width", "narrowest passage of the generated board in cells", func(c *Config) flag.Value { return (*intValue)(&c.CorridorWidth) }},
+	{"battle-royale", "close the border in ring by ring, the last snake alive wins", func(c *Config) flag.Value { return (*boolValue)(&c.BattleRoyale) }},
+	{"shrink-interval", "ticks between two rings of the battle royale border closing", func(c *Config) flag.Value { return (*intValue)(&c.ShrinkInterval) }},
+	{"shrink-warning", "ticks the next ring flashes before it closes", func(c *Config) flag.Value { return (*intValue)(&c.ShrinkWarning) }},
+	{"wrap", "leave the board on one edge and come back in on the opposite edge instead of dying", func(c *Config) flag.Value { return (*boolValue)(&c.Wrap) }},
+	{"menu", "open the main menu before playing with the gocui renderer", func(c *Config) flag.Value { return (*boolValue)(&c.Menu) }},
+	{"keys", "keybindings preset: arrows, vim or wasd", func(c *Config) flag.Value { return (*stringValue)(&c.Keybindings.Preset) }},
+}
+
+func Default() Config {
+	return Config{
+		Difficulty:      "normal",
+		TickInterval:    Duration{0},
+		SpeedStep:       Duration{0},
+		SpeedFloor:      Duration{0},
+		AutoPilot:       false,
+		Strategy:        "greedy",
+		DeltaX:          2,
+		DeltaY:          1,
+		SidePanelWidth:  25,
+		InputQueueDepth: 3,
+		CasualMode:      false,
+		RewindSeconds:   5,
+		Theme:           "dark",
+		Renderer:        "gocui",
+		BoardCols:       30,
+		BoardRows:       20,
+		FitTerminal:     true,
+		GoldenFoodRate:  0.01,
+		GrowthFoodRate:  0.005,
+		PoisonFoodRate:  0.005,
+		GoldenFoodTicks: 40,
+		PoisonFoodTicks: 100,
+		PowerUpRate:     0.005,
+		PowerUpTicks:    50,
+		PowerUpLifetime: 80,
+		SlowMotion:      2,
+		MazeDensity:     -1,
+		CorridorWidth:   2,
+		ShrinkInterval:  100,
+		ShrinkWarning:   20,
+		Menu:            true,
+		Keybindings:     Keybindings{Preset: "arrows", Bindings: map[string][]string{}},
+	}
+}
+
+//Path returns the location of the config file in the XDG config directory.
+func Path() (string, error) {
+	return FilePath(fileName)
+}
+
+//FilePath returns the location of the file with the given name in the snake directory of the XDG config directory,
+//where the config file, the high scores and the campaign progress are kept.
+func FilePath(name string) (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		var err error
+		configDir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(configDir, "snake", name), nil
+}
+
+//Load builds the effective configuration from all layers. It returns the arguments left after the flags.
+func Load(args []string) (Config, []string, error) {
+	flagSet, configPath := newFlagSet()
+	if err := flagSet.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	config := Default()
+	if *configPath == "" {
+		path, err := Path()
+		if err != nil {
+			return Config{}, nil, err
+		}
+		*configPath = path
+	}
+	if err := config.loadFile(*configPath); err != nil {
+		return Config{}, nil, err
+	}
+	loadedPath = *configPath
+	if err := config.loadEnv(); err != nil {
+		return Config{}, nil, err
+	}
+
+	var err error
+	flagSet.Visit(func(f *flag.Flag) {
+		if s, exist := settingByName(f.Name); exist && err == nil {
+			err = s.bind(&config).Set(f.Value.String())
+		}
+	})
+	if err != nil {
+		return Config{}, nil, err
+	}
+	return config, flagSet.Args(), config.validate()
+}
+
+//The flags are parsed into a scratch config, as they can only be applied after the file and environment.
+func newFlagSet() (*flag.FlagSet, *string) {
+	flagSet := flag.NewFlagSet("snake", flag.ContinueOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "Usage: snake [flags]\n       snake [flags] config print\n       snake [flags] export replay.json [-gif out.gif] [-cast out.cast] [-cell-size pixels]\n       snake [flags] edit level.txt\n\nFlags:")
+		flagSet.PrintDefaults()
+	}
+	scratch := Default()
+	for _, s := range settings {
+		flagSet.Var(s.bind(&scratch), s.name, s.usage)
+	}
+	configPath := flagSet.String("config", "", "path to the config file")
+	return flagSet, configPath
+}
+
+func settingByName(name string) (setting, bool) {
+	for _, s := range settings {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+	return nil
+}
+
+//Update applies change to the settings in the config file and saves it, leaving the settings that only come from
+//environment variables and flags out of the file. Only the settings change modifies are written, the settings the
+//file leaves out keep following the defaults.
+func Update(change func(c *Config)) error {
+	path := loadedPath
+	if path == "" {
+		var err error
+		if path, err = Path(); err != nil {
+			return err
+		}
+	}
+	file := make(map[string]interface{})
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+	}
+	config := Default()
+	if err := config.loadFile(path); err != nil {
+		return err
+	}
+	before, err := settingsMap(config)
+	if err != nil {
+		return err
+	}
+	change(&config)
+	if err := config.validate(); err != nil {
+		return err
+	}
+	after, err := settingsMap(config)
+	if err != nil {
+		return err
+	}
+	mergeChanges(file, before, after)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if data, err = json.MarshalIndent(file, "", "  "); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+//Returns the settings as they are written to the config file, by name.
+func settingsMap(c Config) (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]interface{})
+	return settings, json.Unmarshal(data, &settings)
+}
+
+//Copies the values that differ between before and after into file and removes the ones after no longer has. Objects
+//such as the keybindings are merged key by key, so changing the preset leaves the bindings in the file alone.
+func mergeChanges(file map[string]interface{}, before map[string]interface{}, after map[string]interface{}) {
+	for key := range before {
+		if _, exist := after[key]; !exist {
+			delete(file, key)
+		}
+	}
+	for key, value := range after {
+		if reflect.DeepEqual(before[key], value) {
+			continue
+		}
+		beforeObject, wasObject := before[key].(map[string]interface{})
+		afterObject, isObject := value.(map[string]interface{})
+		if !wasObject || !isObject {
+			file[key] = value
+			continue
+		}
+		fileObject, exist := file[key].(map[string]interface{})
+		if !exist {
+			fileObject = make(map[string]interface{})
+		}
+		mergeChanges(fileObject, beforeObject, afterObject)
+		file[key] = fileObject
+	}
+}
+
+func (c *Config) loadEnv() error {
+	for _, s := range settings {
+		name := envName(s.name)
+		value, exist := os.LookupEnv(name)
+		if !exist {
+			continue
+		}
+		if err := s.bind(c).Set(value); err != nil {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func envName(settingName string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(settingName, "-", "_", -1))
+}
+
+func (c *Config) validate() error {
+	if c.TickInterval.Duration != 0 && c.TickInterval.Duration < time.Millisecond {
+		return fmt.Errorf("tick-interval must be 0 or at least 1ms")
+	}
+	if c.SpeedStep.Duration < 0 || c.SpeedFloor.Duration < 0 {
+		return fmt.Errorf("speed-step and speed-floor must not be negative")
+	}
+	if c.DeltaX < 1 || c.DeltaY < 1 {
+		return fmt.Errorf("delta-x and delta-y must be at least 1")
+	}
+	if c.SidePanelWidth < 1 {
+		return fmt.Errorf("side-panel-width must be at least 1")
+	}
+	if c.BoardCols < 2 || c.BoardRows < 2 {
+		return fmt.Errorf("board-cols and board-rows must be at least 2")
+	}
+	if c.InputQueueDepth < 1 {
+		return fmt.Errorf("input-queue-depth must be at least 1")
+	}
+	if c.RewindSeconds < 1 {
+		return fmt.Errorf("rewind-seconds must be at least 1")
+	}
+	for _, rate := range []float64{c.GoldenFoodRate, c.GrowthFoodRate, c.PoisonFoodRate, c.PowerUpRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("food and power-up rates must be between 0 and 1")
+		}
+	}
+	if c.Stage < 0 {
+		return fmt.Errorf("stage must not be negative")
+	}
+	if (c.Campaign && c.Maze != "") || (c.Campaign && c.LevelPath != "") || (c.Maze != "" && c.LevelPath != "") {
+		return fmt.Errorf("only one of campaign, level and maze can be used")
+	}
+	if c.BattleRoyale && (c.Campaign || c.LevelPath != "" || c.Maze != "") {
+		return fmt.Errorf("battle-royale is played on an open board and cannot be combined with campaign, level or maze")
+	}
+	if c.BattleRoyale && c.Wrap {
+		return fmt.Errorf("battle-royale closes the border in and cannot be combined with wrap")
+	}
+	if c.ShrinkInterval < 1 || c.ShrinkWarning < 0 || c.ShrinkWarning >= c.ShrinkInterval {
+		return fmt.Errorf("shrink-interval must be at least 1 and shrink-warning between 0 and shrink-interval")
+	}
+	if c.Strategy != "greedy" && c.Strategy != "cycle" {
+		return fmt.Errorf("unknown autopilot strategy %q", c.Strategy)
+	}
+	if (c.MazeDensity < 0 && c.MazeDensity != -1) || c.MazeDensity > 1 {
+		return fmt.Errorf("maze-density must be between 0 and 1, or -1 for the difficulty's")
+	}
+	if c.CorridorWidth < 1 {
+		return fmt.Errorf("corridor-width must be at least 1")
+	}
+	if c.Hazards < 0 || c.Enemies < 0 || c.Bots < 0 {
+		return fmt.Errorf("hazards, enemies and bots must not be negative")
+	}
+	if c.Bots > 0 && (c.HTTPAddress == "" || !c.HTTPControl) {
+		return fmt.Errorf("bots steer their snakes over the spectator websocket and need http and http-control")
+	}
+	if c.GoldenFoodTicks < 1 || c.PoisonFoodTicks < 1 || c.PowerUpTicks < 1 || c.PowerUpLifetime < 1 {
+		return fmt.Errorf("golden-food-ticks, poison-food-ticks, power-up-ticks and power-up-lifetime must be at least 1")
+	}
+	if c.SlowMotion < 1 {
+		return fmt.Errorf("slow-motion must be at least 1")
+	}
+	return nil
+}
+
+//Print writes the effective configuration in the config file format.
+func (c Config) Print(w io.Writer) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
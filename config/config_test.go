@@ -0,0 +1,196 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+//Creates a config file with the given contents in a new directory and returns its path, or a path to a file that
+//does not exist if contents is empty. The directory is removed by the returned function.
+func tempConfigFile(t *testing.T, contents string) (string, func()) {
+	dir, err := ioutil.TempDir("", "snake-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, fileName)
+	if contents != "" {
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestLoadSettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		env   map[string]string
+		args  []string
+		check func(c Config) bool
+	}{
+		{"defaults", "", nil, nil, func(c Config) bool {
+			return reflect.DeepEqual(c, Default())
+		}},
+		{"file", `{"Theme": "light", "TickInterval": "80ms"}`, nil, nil, func(c Config) bool {
+			return c.Theme == "light" && c.TickInterval.Duration == 80*time.Millisecond
+		}},
+		{"environment beats file", `{"Difficulty": "hard"}`, map[string]string{"SNAKE_DIFFICULTY": "easy"}, nil, func(c Config) bool {
+			return c.Difficulty == "easy"
+		}},
+		{"flag beats environment beats file", `{"Theme": "light"}`, map[string]string{"SNAKE_THEME": "high-contrast"}, []string{"-theme", "monochrome"}, func(c Config) bool {
+			return c.Theme == "monochrome"
+		}},
+		{"tick interval", "", nil, []string{"-tick-interval", "80ms"}, func(c Config) bool {
+			return c.TickInterval.Duration == 80*time.Millisecond
+		}},
+		{"rewind seconds", "", nil, []string{"-rewind-seconds", "1"}, func(c Config) bool {
+			return c.RewindSeconds == 1
+		}},
+		{"poison food ticks flag", "", nil, []string{"-poison-food-ticks", "30"}, func(c Config) bool {
+			return c.PoisonFoodTicks == 30
+		}},
+		{"power-up lifetime from the file", `{"PowerUpLifetime": 12}`, nil, nil, func(c Config) bool {
+			return c.PowerUpLifetime == 12
+		}},
+		{"slow motion from the environment", "", map[string]string{"SNAKE_SLOW_MOTION": "3"}, nil, func(c Config) bool {
+			return c.SlowMotion == 3
+		}},
+		{"open maze", "", nil, []string{"-maze-density", "0"}, func(c Config) bool {
+			return c.MazeDensity == 0
+		}},
+		{"difficulty's maze density", "", nil, nil, func(c Config) bool {
+			return c.MazeDensity == -1
+		}},
+		{"bots with control mode", "", nil, []string{"-bots", "2", "-http", ":8080", "-http-control"}, func(c Config) bool {
+			return c.Bots == 2 && c.HTTPControl
+		}},
+		{"fixed board size", "", nil, []string{"-fit-terminal=false", "-board-cols", "40"}, func(c Config) bool {
+			return !c.FitTerminal && c.BoardCols == 40
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, remove := tempConfigFile(t, test.file)
+			defer remove()
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+			c, _, err := Load(append([]string{"-config", path}, test.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.check(c) {
+				t.Errorf("unexpected config %+v", c)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no rewind", []string{"-rewind-seconds", "0"}},
+		{"negative rewind", []string{"-rewind-seconds", "-1"}},
+		{"poison that never shows", []string{"-poison-food-ticks", "0"}},
+		{"power-up that never shows", []string{"-power-up-lifetime", "0"}},
+		{"no slow motion", []string{"-slow-motion", "0"}},
+		{"negative maze density", []string{"-maze-density", "-0.5"}},
+		{"wrap in battle royale", []string{"-battle-royale", "-wrap"}},
+		{"bots without control mode", []string{"-bots", "1", "-http", ":8080"}},
+		{"negative bots", []string{"-bots", "-1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, remove := tempConfigFile(t, "")
+			defer remove()
+			if _, _, err := Load(append([]string{"-config", path}, test.args...)); err == nil {
+				t.Errorf("Load(%v) accepted invalid settings", test.args)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		change func(c *Config)
+		//The config file after the update
+		want string
+	}{
+		{"new file", "", func(c *Config) { c.Wrap = true }, `{"Wrap": true}`},
+		{"keeps other settings", `{"Theme": "light"}`, func(c *Config) { c.Difficulty = "hard" },
+			`{"Theme": "light", "Difficulty": "hard"}`},
+		{"unchanged setting", `{"Theme": "light"}`, func(c *Config) { c.Theme = "light" }, `{"Theme": "light"}`},
+		{"back to the default", `{"BoardCols": 40}`, func(c *Config) { c.BoardCols = 30 }, `{"BoardCols": 30}`},
+		{"keybindings preset", `{"Keybindings": {"Bindings": {"up": ["k"]}}}`, func(c *Config) { c.Keybindings.Preset = "vim" },
+			`{"Keybindings": {"Preset": "vim", "Bindings": {"up": ["k"]}}}`},
+		{"durations", "", func(c *Config) { c.TickInterval.Duration = 90 * time.Millisecond }, `{"TickInterval": "90ms"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, remove := tempConfigFile(t, test.file)
+			defer remove()
+			before, _, err := Load([]string{"-config", path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Update(test.change); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(test.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("config file is %s, want %s", data, test.want)
+			}
+
+			//Loading the file again gives the settings before the update with the change applied
+			after, _, err := Load([]string{"-config", path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.change(&before)
+			if !reflect.DeepEqual(after, before) {
+				t.Errorf("loaded %+v after the update, want %+v", after, before)
+			}
+		})
+	}
+}
+
+func TestUpdateRejectsInvalidChange(t *testing.T) {
+	path, remove := tempConfigFile(t, `{"Theme": "light"}`)
+	defer remove()
+	if _, _, err := Load([]string{"-config", path}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(func(c *Config) { c.RewindSeconds = 0 }); err == nil {
+		t.Error("Update accepted an invalid change")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Theme": "light"}` {
+		t.Errorf("config file changed to %s", data)
+	}
+}
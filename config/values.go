@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+//flag.Value implementations that write straight into the fields of a Config.
+
+type durationValue time.Duration
+
+func (d *durationValue) Set(value string) error {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(duration)
+	return nil
+}
+
+func (d *durationValue) String() string { return (*time.Duration)(d).String() }
+
+type intValue int
+
+func (i *intValue) Set(value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*i = intValue(parsed)
+	return nil
+}
+
+func (i *intValue) String() string { return strconv.Itoa(int(*i)) }
+
+type floatValue float64
+
+func (f *floatValue) Set(value string) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*f = floatValue(parsed)
+	return nil
+}
+
+func (f *floatValue) String() string { return strconv.FormatFloat(float64(*f), 'g', -1, 64) }
+
+type boolValue bool
+
+func (b *boolValue) Set(value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*b = boolValue(parsed)
+	return nil
+}
+
+func (b *boolValue) String() string { return strconv.FormatBool(bool(*b)) }
+
+func (b *boolValue) IsBoolFlag() bool { return true }
+
+type stringValue string
+
+func (s *stringValue) Set(value string) error {
+	*s = stringValue(value)
+	return nil
+}
+
+func (s *stringValue) String() string { return string(*s) }
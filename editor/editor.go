@@ -0,0 +1,228 @@
+package editor
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/campaign"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/game/view"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	boardViewName  = "editor"
+	keysViewName   = "editorKeys"
+	statusViewName = "editorStatus"
+	checkViewName  = "editorCheck"
+)
+
+var (
+	path           string
+	stage          game.Stage
+	walls          map[game.Cell]bool
+	cursor         game.Cell
+	pendingPortal  *game.Cell
+	modified       = false
+	message        = ""
+	board          = &view.Board{}
+	positionMatrix [][]game.Position
+	directionRunes = map[game.Direction]rune{
+		game.Directions.Up:    '↑',
+		game.Directions.Right: '→',
+		game.Directions.Down:  '↓',
+		game.Directions.Left:  '←',
+	}
+	cursorStyle    = view.CellStyle{Glyph: '+', Fg: gocui.ColorBlack, Bg: gocui.ColorWhite}
+	unreachedStyle = view.CellStyle{Glyph: '·', Fg: gocui.ColorRed, Bg: gocui.ColorDefault}
+)
+
+//Run opens the level at levelPath in the editor, or a new empty level of cols by rows cells if the file does not
+//exist yet, and returns when the editor is closed.
+func Run(levelPath string, cols int, rows int) error {
+	path = levelPath
+	if err := load(cols, rows); err != nil {
+		return err
+	}
+
+	gui, err := gocui.NewGui(view.DetectOutputMode(), true)
+	if err != nil {
+		return err
+	}
+	defer gui.Close()
+	gui.SetManagerFunc(layout)
+	if err := initKeybindings(gui); err != nil {
+		return err
+	}
+	if err := gui.MainLoop(); err != nil && !gocui.IsQuit(err) {
+		return err
+	}
+	return nil
+}
+
+//Reads the level file, starting a new level if there is none.
+func load(cols int, rows int) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		stage = game.Stage{
+			Cols:           cols,
+			Rows:           rows,
+			HasStart:       true,
+			Start:          game.Cell{Col: cols / 2, Row: rows / 2},
+			StartDirection: game.Directions.Right,
+			StartLength:    1,
+			Speed:          100 * time.Millisecond,
+			Goal:           game.Goal{Kind: game.GoalKinds.Length, Target: 20},
+		}
+		message = "New level"
+	} else if err != nil {
+		return err
+	} else {
+		if stage, err = campaign.Parse(string(data)); err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+		message = "Loaded"
+	}
+	walls = make(map[game.Cell]bool)
+	for _, cell := range stage.Walls {
+		walls[cell] = true
+	}
+	cursor = game.Cell{Col: stage.Cols / 2, Row: stage.Rows / 2}
+	pendingPortal = nil
+	modified = false
+	return nil
+}
+
+func save() error {
+	if err := ioutil.WriteFile(path, []byte(campaign.Format(currentStage())), 0644); err != nil {
+		return err
+	}
+	modified = false
+	message = "Saved"
+	return nil
+}
+
+//Returns the level as edited so far, with the walls in reading order.
+func currentStage() game.Stage {
+	edited := stage
+	edited.Walls = []game.Cell{}
+	for cell := range walls {
+		edited.Walls = append(edited.Walls, cell)
+	}
+	sort.Slice(edited.Walls, func(i, j int) bool {
+		a, b := edited.Walls[i], edited.Walls[j]
+		return a.Row < b.Row || (a.Row == b.Row && a.Col < b.Col)
+	})
+	return edited
+}
+
+func layout(gui *gocui.Gui) error {
+	boardPosition := game.Position{X0: 0, Y0: 0, X1: stage.Cols * game.DeltaX, Y1: stage.Rows * game.DeltaY}
+	if v, err := gui.SetView(boardViewName, boardPosition.X0, boardPosition.Y0, boardPosition.X1, boardPosition.Y1, 0); err != nil {
+		if !gocui.IsUnknownView(err) {
+			return err
+		}
+		v.Title = "Level editor"
+		v.BgColor = view.BackgroundColor()
+		if _, err := gui.SetCurrentView(boardViewName); err != nil {
+			return err
+		}
+	}
+
+	positionMatrix = game.GeneratePositionMatrix(boardPosition)
+
+	sideX := boardPosition.X1 + 1
+	keysView, err := setSideView(gui, keysViewName, "Keys", sideX, 0, len(keyHelp)+1)
+	if err != nil {
+		return err
+	}
+	keysView.Clear()
+	fmt.Fprint(keysView, strings.Join(keyHelp, "\n"))
+
+	statusView, err := setSideView(gui, statusViewName, "Level", sideX, len(keyHelp)+2, len(keyHelp)+7)
+	if err != nil {
+		return err
+	}
+	statusView.Clear()
+	fmt.Fprintln(statusView, path)
+	fmt.Fprintf(statusView, "Cursor:%d,%d\n", cursor.Col+1, cursor.Row+1)
+	fmt.Fprintf(statusView, "Start:%v length %d\n", string(directionRunes[stage.StartDirection]), stage.StartLength)
+	if modified {
+		fmt.Fprintln(statusView, "Unsaved changes")
+	}
+	fmt.Fprint(statusView, message)
+
+	problems := campaign.Check(currentStage())
+	_, maxY := gui.Size()
+	checkView, err := setSideView(gui, checkViewName, "Check", sideX, len(keyHelp)+8, maxY-1)
+	if err != nil {
+		return err
+	}
+	checkView.Wrap = true
+	checkView.Clear()
+	if len(problems) == 0 {
+		fmt.Fprint(checkView, "No problems")
+	} else {
+		fmt.Fprint(checkView, strings.Join(problems, "\n"))
+	}
+	return drawBoard(gui)
+}
+
+func setSideView(gui *gocui.Gui, name string, title string, x int, y0 int, y1 int) (*gocui.View, error) {
+	v, err := gui.SetView(name, x, y0, x+view.SidePanelWidth, y1, 0)
+	if err != nil {
+		if !gocui.IsUnknownView(err) {
+			return nil, err
+		}
+		v.Title = title
+	}
+	return v, nil
+}
+
+//Draws the walls, the portals with the letter of their pair, the start with its direction, the floor that cannot be
+//reached from the start and the cursor.
+func drawBoard(gui *gocui.Gui) error {
+	board.Resize(boardViewName, positionMatrix)
+	board.Clear()
+	edited := currentStage()
+	reachable := campaign.ReachableCells(edited)
+	for cell := range campaign.FloorCells(edited) {
+		if !reachable[cell] {
+			board.SetCell(cell.Col, cell.Row, unreachedStyle)
+		}
+	}
+	for cell := range walls {
+		board.SetCell(cell.Col, cell.Row, view.WallStyle())
+	}
+	portalGlyphs := make(map[game.Cell]rune)
+	for i, pair := range stage.Portals {
+		portalGlyphs[pair[0]] = campaign.FirstPortalRune + rune(i)
+		portalGlyphs[pair[1]] = campaign.FirstPortalRune + rune(i)
+	}
+	if pendingPortal != nil {
+		portalGlyphs[*pendingPortal] = '?'
+	}
+	for cell, glyph := range portalGlyphs {
+		style := view.PortalStyle()
+		style.Glyph = glyph
+		board.SetCell(cell.Col, cell.Row, style)
+	}
+	if stage.HasStart {
+		style := view.SnakeStyle(0, 1)
+		style.Glyph = directionRunes[stage.StartDirection]
+		board.SetCell(stage.Start.Col, stage.Start.Row, style)
+	}
+	style := cursorStyle
+	if stage.HasStart && cursor == stage.Start {
+		style.Glyph = directionRunes[stage.StartDirection]
+	} else if walls[cursor] {
+		style.Glyph = '#'
+	} else if glyph, exist := portalGlyphs[cursor]; exist {
+		style.Glyph = glyph
+	}
+	board.SetCell(cursor.Col, cursor.Row, style)
+	return board.Draw(gui)
+}
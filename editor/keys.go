@@ -0,0 +1,167 @@
+package editor
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/campaign"
+	"github.com/eiba/snake/game"
+)
+
+const (
+	unsavedMessage       = "Unsaved changes, press Esc again to exit"
+	unsavedReloadMessage = "Unsaved changes, press Ctrl+R again to reload"
+)
+
+var keyHelp = []string{
+	"←↑→↓: Move cursor",
+	"Space: Toggle wall",
+	"S: Place start",
+	"D: Turn start",
+	"P: Place portal end",
+	"+/-: Starting length",
+	"X: Clear cell",
+	"Ctrl+S: Save",
+	"Ctrl+R: Reload file",
+	"Esc: Exit",
+}
+
+func initKeybindings(gui *gocui.Gui) error {
+	for key, direction := range map[gocui.Key]game.Cell{
+		gocui.KeyArrowUp:    {Col: 0, Row: -1},
+		gocui.KeyArrowRight: {Col: 1, Row: 0},
+		gocui.KeyArrowDown:  {Col: 0, Row: 1},
+		gocui.KeyArrowLeft:  {Col: -1, Row: 0},
+	} {
+		if err := initMoveKey(gui, key, direction); err != nil {
+			return err
+		}
+	}
+	bindings := []struct {
+		key     interface{}
+		handler func(gui *gocui.Gui, v *gocui.View) error
+	}{
+		{gocui.KeySpace, edit(toggleWall)},
+		{'s', edit(placeStart)},
+		{'d', edit(turnStart)},
+		{'p', edit(placePortal)},
+		{'+', edit(func() { stage.StartLength++ })},
+		{'-', edit(func() {
+			if stage.StartLength > 1 {
+				stage.StartLength--
+			}
+		})},
+		{'x', edit(clearCell)},
+		{gocui.KeyCtrlS, func(gui *gocui.Gui, v *gocui.View) error {
+			if err := save(); err != nil {
+				message = err.Error()
+			}
+			return nil
+		}},
+		{gocui.KeyCtrlR, reload},
+		{gocui.KeyEsc, quit},
+		{gocui.KeyCtrlC, func(gui *gocui.Gui, v *gocui.View) error { return gocui.ErrQuit }},
+	}
+	for _, binding := range bindings {
+		if err := gui.SetKeybinding("", binding.key, gocui.ModNone, binding.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func initMoveKey(gui *gocui.Gui, key gocui.Key, offset game.Cell) error {
+	return gui.SetKeybinding("", key, gocui.ModNone, func(gui *gocui.Gui, v *gocui.View) error {
+		next := game.Cell{Col: cursor.Col + offset.Col, Row: cursor.Row + offset.Row}
+		if next.Col >= 0 && next.Col < stage.Cols && next.Row >= 0 && next.Row < stage.Rows {
+			cursor = next
+		}
+		return nil
+	})
+}
+
+//Wraps a change to the level into a key handler that marks the level as modified.
+func edit(change func()) func(gui *gocui.Gui, v *gocui.View) error {
+	return func(gui *gocui.Gui, v *gocui.View) error {
+		message = ""
+		change()
+		modified = true
+		return nil
+	}
+}
+
+func toggleWall() {
+	if walls[cursor] {
+		delete(walls, cursor)
+		return
+	}
+	clearCell()
+	walls[cursor] = true
+}
+
+func placeStart() {
+	clearCell()
+	stage.HasStart = true
+	stage.Start = cursor
+}
+
+//Turns the start clockwise.
+func turnStart() {
+	stage.StartDirection = (stage.StartDirection + 1) % 4
+}
+
+//Places one end of a portal pair at the cursor. The pair is complete once the other end is placed at another cell.
+func placePortal() {
+	if pendingPortal != nil && *pendingPortal == cursor {
+		pendingPortal = nil
+		return
+	}
+	if pendingPortal == nil && len(stage.Portals) >= campaign.MaxPortalPairs {
+		message = fmt.Sprintf("There can only be %d portal pairs", campaign.MaxPortalPairs)
+		return
+	}
+	first := pendingPortal
+	clearCell()
+	if first == nil {
+		cell := cursor
+		pendingPortal = &cell
+		message = "Place the other end with P"
+		return
+	}
+	stage.Portals = append(stage.Portals, [2]game.Cell{*first, cursor})
+	pendingPortal = nil
+}
+
+//Removes the wall, the start or the portal pair at the cursor.
+func clearCell() {
+	delete(walls, cursor)
+	if stage.HasStart && stage.Start == cursor {
+		stage.HasStart = false
+	}
+	if pendingPortal != nil && *pendingPortal == cursor {
+		pendingPortal = nil
+	}
+	for i, pair := range stage.Portals {
+		if pair[0] == cursor || pair[1] == cursor {
+			stage.Portals = append(stage.Portals[:i], stage.Portals[i+1:]...)
+			return
+		}
+	}
+}
+
+//Reloads the level from its file, asking for a second press if there are unsaved changes.
+func reload(gui *gocui.Gui, v *gocui.View) error {
+	if modified && message != unsavedReloadMessage {
+		message = unsavedReloadMessage
+		return nil
+	}
+	return load(stage.Cols, stage.Rows)
+}
+
+//Exits the editor, asking for a second press if there are unsaved changes.
+func quit(gui *gocui.Gui, v *gocui.View) error {
+	if modified && message != unsavedMessage {
+		message = unsavedMessage
+		return nil
+	}
+	return gocui.ErrQuit
+}
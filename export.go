@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+	"github.com/eiba/snake/replay"
+	"os"
+	"strings"
+)
+
+//Exports a replay saved with -record as an animated GIF and/or an asciinema cast.
+//The replay file may be given before or after the flags.
+func exportReplay(args []string) error {
+	flagSet := flag.NewFlagSet("export", flag.ContinueOnError)
+	gifPath := flagSet.String("gif", "", "write the replay as an animated GIF to this file")
+	castPath := flagSet.String("cast", "", "write the replay as an asciinema v2 cast to this file")
+	cellSize := flagSet.Int("cell-size", 8, "size of a board cell in the GIF in pixels")
+
+	var replayPath string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		replayPath, args = args[0], args[1:]
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if replayPath == "" {
+		replayPath = flagSet.Arg(0)
+	}
+	if replayPath == "" {
+		return fmt.Errorf("export: missing replay file")
+	}
+	if *gifPath == "" && *castPath == "" {
+		return fmt.Errorf("export: nothing to do, give -gif and/or -cast")
+	}
+	if *cellSize < 1 {
+		return fmt.Errorf("export: cell-size must be at least 1")
+	}
+
+	r, err := replay.Load(replayPath)
+	if err != nil {
+		return err
+	}
+	//Exports are viewed outside the terminal they were made in, so they always use the full palette
+	view.UseOutputMode(gocui.Output256)
+	if *gifPath != "" {
+		if err := writeFile(*gifPath, func(f *os.File) error { return replay.WriteGIF(f, r, *cellSize) }); err != nil {
+			return err
+		}
+	}
+	if *castPath != "" {
+		if err := writeFile(*castPath, func(f *os.File) error { return replay.WriteCast(f, r) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,180 @@
+package game
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+)
+
+var (
+	//Whether the border closes in and the last snake alive wins
+	BattleRoyale = false
+	//Ticks between two rings of the board closing
+	ShrinkInterval = 100
+	//Ticks the next ring flashes before it closes
+	ShrinkWarningTicks = 20
+	//Enemy snakes that join the battle royale when no enemy count is configured
+	BattleRoyaleRivals = 3
+	//Rings of the board that have closed so far, counted from the border
+	arenaRing = 0
+)
+
+//Ticks until the next ring closes.
+func ticksToShrink() int {
+	return ShrinkInterval - Tick%ShrinkInterval
+}
+
+//Whether another ring can close and still leave room to move. The innermost two columns or rows never close.
+func arenaCanShrink() bool {
+	return 2*(arenaRing+1) < boardCols && 2*(arenaRing+1) < boardRows
+}
+
+func arenaWarning() bool {
+	return BattleRoyale && arenaCanShrink() && ticksToShrink() <= ShrinkWarningTicks
+}
+
+//Whether cell lies in a ring that has closed, on a board of cols by rows cells.
+func ringClosed(cell Cell, ring int, cols int, rows int) bool {
+	return cell.Col < ring || cell.Row < ring || cell.Col >= cols-ring || cell.Row >= rows-ring
+}
+
+func arenaClosed(position Position) bool {
+	return BattleRoyale && ringClosed(CellOf(position), arenaRing, boardCols, boardRows)
+}
+
+//ArenaCells returns the cells of the rings that have closed and the cells of the ring that flashes before it closes,
+//empty on the frames the warning is off.
+func (s State) ArenaCells() ([]Cell, []Cell) {
+	var closed, warned []Cell
+	for col := 0; col < s.Cols; col++ {
+		for row := 0; row < s.Rows; row++ {
+			cell := Cell{col, row}
+			if ringClosed(cell, s.ArenaRing, s.Cols, s.Rows) {
+				closed = append(closed, cell)
+			} else if s.ArenaWarning && ringClosed(cell, s.ArenaRing+1, s.Cols, s.Rows) {
+				warned = append(warned, cell)
+			}
+		}
+	}
+	return closed, warned
+}
+
+//Cells the autopilot has to leave and food must not spawn in: the closed rings and, while it flashes, the next ring.
+func arenaObstacles() map[Position]bool {
+	obstacles := make(map[Position]bool)
+	if !BattleRoyale {
+		return obstacles
+	}
+	ring := arenaRing
+	if arenaWarning() {
+		ring++
+	}
+	for col := 0; col < boardCols; col++ {
+		for row := 0; row < boardRows; row++ {
+			cell := Cell{col, row}
+			if ringClosed(cell, ring, boardCols, boardRows) {
+				obstacles[Position{col * DeltaX, row * DeltaY, (col + 1) * DeltaX, (row + 1) * DeltaY}] = true
+			}
+		}
+	}
+	return obstacles
+}
+
+func resetArena() {
+	arenaRing = 0
+}
+
+//Closes the next ring when it is due, removing everything caught in it, and ends the run once the player's snake
+//is the only one left. Called once per tick, after the snakes have moved. Returns true if the run ended,
+//either because the player's snake was caught by the border or because it won.
+func UpdateArena(gui *gocui.Gui, positionMatrix [][]Position) (bool, error) {
+	if !BattleRoyale || runOver {
+		return false, nil
+	}
+	if Tick%ShrinkInterval == 0 && arenaCanShrink() {
+		arenaRing++
+		if err := closeRing(gui, positionMatrix); err != nil || runOver {
+			return runOver, err
+		}
+	}
+	if err := view.UpdateGoal(goalProgress()); err != nil {
+		return false, err
+	}
+	if EnemyCount+BotCount == 0 || len(enemies) > 0 || len(enemyRespawns) > 0 {
+		return false, nil
+	}
+	runOver = true
+	deathCause = ""
+	if err := summarize(); err != nil {
+		return false, err
+	}
+	if err := recordRun(); err != nil {
+		return false, err
+	}
+	if err := view.SetGameOverText(fmt.Sprint(actionLabel(ActionRestart), ": play again"), gameOverActions()); err != nil {
+		return false, err
+	}
+	return true, view.GameOver(gui, "Last snake standing!")
+}
+
+func closeRing(gui *gocui.Gui, positionMatrix [][]Position) error {
+	for _, bodyPart := range SnakeBodyParts {
+		if arenaClosed(bodyPart.position) {
+			deathCause = "caught by the closing border"
+			if err := endRun(); err != nil {
+				return err
+			}
+			return view.GameOver(gui, "Caught by the border")
+		}
+	}
+
+	remainingEnemies := enemies[:0]
+	for _, e := range enemies {
+		caught := false
+		for _, position := range e.body {
+			caught = caught || arenaClosed(position)
+		}
+		if !caught {
+			remainingEnemies = append(remainingEnemies, e)
+		}
+	}
+	enemies = remainingEnemies
+	remainingPatrols := patrols[:0]
+	for _, p := range patrols {
+		if !arenaClosed(p.position) {
+			remainingPatrols = append(remainingPatrols, p)
+		}
+	}
+	patrols = remainingPatrols
+	remainingPowerUps := powerUps[:0]
+	for _, p := range powerUps {
+		if !arenaClosed(p.position) {
+			remainingPowerUps = append(remainingPowerUps, p)
+		}
+	}
+	powerUps = remainingPowerUps
+
+	regularLost := false
+	remainingFoods := foods[:0]
+	for _, f := range foods {
+		if !arenaClosed(f.position) {
+			remainingFoods = append(remainingFoods, f)
+		} else if f.foodType == FoodTypes.Regular {
+			regularLost = true
+		}
+	}
+	foods = remainingFoods
+	if regularLost {
+		if position, found := tryGetFreePosition(positionMatrix); found {
+			foods = append(foods, food{FoodTypes.Regular, position, Tick})
+		}
+	}
+	return nil
+}
+
+func arenaProgress() string {
+	if !arenaCanShrink() {
+		return fmt.Sprintf("%d rivals", len(enemies))
+	}
+	return fmt.Sprintf("%d rivals, shrink in %d", len(enemies), ticksToShrink())
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game/view"
+	"time"
+)
+
+//A speed curve: the tick interval starts at Start and is shortened by Step for every StepLength segments
+//the snake grows, down to Floor.
+type Difficulty struct {
+	Name       string
+	Start      time.Duration
+	Step       time.Duration
+	StepLength int
+	Floor      time.Duration
+	//How much of a generated maze is wall, from 0 to 1
+	MazeDensity float64
+}
+
+const manualSpeedChange = 10 * time.Millisecond
+
+var (
+	difficulties = map[string]Difficulty{
+		"easy": {Name: "easy", Start: 120 * time.Millisecond, Step: 5 * time.Millisecond, StepLength: 5,
+			Floor: 60 * time.Millisecond, MazeDensity: 0.2},
+		"normal": {Name: "normal", Start: 100 * time.Millisecond, Step: 5 * time.Millisecond, StepLength: 3,
+			Floor: 40 * time.Millisecond, MazeDensity: 0.4},
+		"hard": {Name: "hard", Start: 70 * time.Millisecond, Step: 5 * time.Millisecond, StepLength: 2,
+			Floor: 25 * time.Millisecond, MazeDensity: 0.6},
+		"insane": {Name: "insane", Start: 40 * time.Millisecond, Step: 2 * time.Millisecond, StepLength: 1,
+			Floor: 10 * time.Millisecond, MazeDensity: 0.8},
+	}
+	CurrentDifficulty = difficulties["normal"]
+	//Added to the curve by the speed keys
+	speedOffset = time.Duration(0)
+	//Whether the speed keys were used during the run, which flags it in the high score table
+	speedOverridden = false
+	//The starting speed of the stage being played, which takes the place of the difficulty's. Zero outside stages.
+	stageStart = time.Duration(0)
+)
+
+func UseDifficulty(name string) error {
+	difficulty, exist := difficulties[name]
+	if !exist {
+		return fmt.Errorf("unknown difficulty %q", name)
+	}
+	CurrentDifficulty = difficulty
+	return nil
+}
+
+//Returns the tick interval for the current length of the snake, including manual speed changes.
+func CurveTickInterval() time.Duration {
+	d := CurrentDifficulty
+	interval := d.Start
+	if stageStart > 0 {
+		interval = stageStart
+	}
+	if d.StepLength > 0 {
+		interval -= d.Step * time.Duration((len(SnakeBodyParts)-1)/d.StepLength)
+	}
+	if interval < d.Floor {
+		interval = d.Floor
+	}
+	interval += speedOffset
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	return interval
+}
+
+//Makes the game faster or slower than the curve, for the rest of the run.
+func AdjustSpeed(faster bool) {
+	if faster {
+		speedOffset -= manualSpeedChange
+	} else {
+		speedOffset += manualSpeedChange
+	}
+	speedOverridden = true
+}
+
+//Shows the speed of the game in the stats view.
+func updateSpeedStat() error {
+	return view.UpdateSpeed(NextTickInterval(TickInterval), speedOverridden)
+}
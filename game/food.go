@@ -5,18 +5,236 @@ import (
 	"github.com/eiba/snake/game/view"
 )
 
-var foodView = view.Properties{"food", "", "", Position{}}
+type FoodType int
+type foodTypes struct {
+	Regular FoodType
+	Golden  FoodType
+	Growth  FoodType
+	Poison  FoodType
+}
+
+//What eating a food of a type does to the snake and the score
+type foodKind struct {
+	name string
+	//Segments added to the snake, negative values shrink it
+	growth int
+	//Points relative to a regular food
+	value float64
+	//Ticks until the food disappears, 0 if it stays until it is eaten
+	lifetime *int
+}
+
+type food struct {
+	foodType  FoodType
+	position  Position
+	spawnTick int
+}
+
+var (
+	FoodTypes = foodTypes{0, 1, 2, 3}
+	//Chance per tick that a food of the type appears while there is none on the board.
+	//There is always exactly one regular food.
+	FoodSpawnRates = map[FoodType]float64{
+		FoodTypes.Golden: 0.01,
+		FoodTypes.Growth: 0.005,
+		FoodTypes.Poison: 0.005,
+	}
+	GoldenFoodTicks = 40
+	PoisonFoodTicks = 100
+	foodKinds       = map[FoodType]foodKind{
+		FoodTypes.Regular: {"regular", 1, 1, nil},
+		FoodTypes.Golden:  {"golden", 1, 5, &GoldenFoodTicks},
+		FoodTypes.Growth:  {"growth", 3, 2, nil},
+		FoodTypes.Poison:  {"poison", -2, 0, &PoisonFoodTicks},
+	}
+	foods []food
+)
+
+func (t FoodType) String() string {
+	return foodKinds[t].name
+}
+
+//Ticks until f disappears, or -1 if it does not expire.
+func (f food) ticksLeft() int {
+	lifetime := foodKinds[f.foodType].lifetime
+	if lifetime == nil {
+		return -1
+	}
+	return f.spawnTick + *lifetime - Tick
+}
+
+func (f food) expired() bool {
+	return foodKinds[f.foodType].lifetime != nil && f.ticksLeft() <= 0
+}
+
+//Replaces all food with a single regular food.
+func ResetFoods(positionMatrix [][]Position) {
+	foods = nil
+	position, found := tryGetFreePosition(positionMatrix)
+	if !found {
+		position = view.GetRandomPosition(positionMatrix)
+	}
+	foods = []food{{FoodTypes.Regular, position, Tick}}
+}
+
+func foodIndexAt(position Position) int {
+	for i, f := range foods {
+		if f.position == position {
+			return i
+		}
+	}
+	return -1
+}
+
+//Removes expired food and spawns the special food types according to their spawn rates. Called once per tick.
+func UpdateFoods(positionMatrix [][]Position) {
+	remaining := foods[:0]
+	for _, f := range foods {
+		if !f.expired() {
+			remaining = append(remaining, f)
+		}
+	}
+	foods = remaining
+
+	for _, foodType := range []FoodType{FoodTypes.Golden, FoodTypes.Growth, FoodTypes.Poison} {
+		if hasFood(foodType) || r.Float64() >= FoodSpawnRates[foodType] {
+			continue
+		}
+		if position, found := tryGetFreePosition(positionMatrix); found {
+			foods = append(foods, food{foodType, position, Tick})
+		}
+	}
+}
+
+func hasFood(foodType FoodType) bool {
+	for _, f := range foods {
+		if f.foodType == foodType {
+			return true
+		}
+	}
+	return false
+}
 
-func eatFood(gui *gocui.Gui, positionMatrix [][]Position) (error, bool) {
-	err := addBodyPartToEnd(*SnakeBodyParts[len(SnakeBodyParts)-1])
-	if err != nil {
+//Finds a random position that is not taken by the snake, a wall, a portal, a hazard, food or a power-up.
+func tryGetFreePosition(positionMatrix [][]Position) (Position, bool) {
+	return tryGetFreePositionExcluding(positionMatrix, nil)
+}
+
+//Finds a random free position that is not in excluded either.
+func tryGetFreePositionExcluding(positionMatrix [][]Position, excluded map[Position]bool) (Position, bool) {
+	occupied := ObstacleSet()
+	for position := range excluded {
+		occupied[position] = true
+	}
+	for _, f := range foods {
+		occupied[f.position] = true
+	}
+	for _, p := range powerUps {
+		occupied[p.position] = true
+	}
+	for position := range portals {
+		occupied[position] = true
+	}
+	return view.TryGetRandomFreePosition(positionMatrix, occupied)
+}
+
+func eatFood(gui *gocui.Gui, positionMatrix [][]Position, index int) (error, bool) {
+	eaten := foods[index]
+	foods = append(foods[:index], foods[index+1:]...)
+	kind := foodKinds[eaten.foodType]
+	foodEaten++
+	lastFoodTick = Tick
+	if eaten.foodType == FoodTypes.Golden {
+		goldenEaten++
+	}
+	for i := 0; i < kind.growth; i++ {
+		err := addBodyPartToEnd(*SnakeBodyParts[len(SnakeBodyParts)-1])
+		if err != nil {
+			return err, false
+		}
+	}
+	if kind.growth < 0 {
+		if err := shrink(-kind.growth); err != nil {
+			return err, false
+		}
+	}
+	if err := scoreFood(positionMatrix, eaten); err != nil {
 		return err, false
 	}
+	if eaten.foodType != FoodTypes.Regular {
+		return nil, true
+	}
 
-	var foundEmptyPosition bool
-	foodView.Position, foundEmptyPosition, err = view.TrySetViewAtRandomEmptyPosition(gui, foodView.Name, positionMatrix)
+	position, foundEmptyPosition := tryGetFreePosition(positionMatrix)
 	if !foundEmptyPosition {
+		position, foundEmptyPosition = clearSpecialCell()
+	}
+	if !foundEmptyPosition {
+		deathCause = ""
+		if err := endRun(); err != nil {
+			return err, false
+		}
 		return view.GameOver(gui, "Game Won!"), false
 	}
-	return err, true
+	foods = append(foods, food{FoodTypes.Regular, position, Tick})
+	return nil, true
+}
+
+//Removes a special food or a power-up to make room for the regular food, as the game is only won once the snake
+//fills every cell it can reach. Returns false if there is nothing to remove.
+func clearSpecialCell() (Position, bool) {
+	for i, f := range foods {
+		if f.foodType != FoodTypes.Regular {
+			foods = append(foods[:i], foods[i+1:]...)
+			return f.position, true
+		}
+	}
+	if len(powerUps) > 0 {
+		position := powerUps[0].position
+		powerUps = powerUps[1:]
+		return position, true
+	}
+	return Position{}, false
+}
+
+//Removes up to segments segments from the end of the snake, always keeping the head.
+func shrink(segments int) error {
+	length := len(SnakeBodyParts) - segments
+	if length < 1 {
+		length = 1
+	}
+	SnakeBodyParts = SnakeBodyParts[:length]
+	return view.UpdateStat(&view.LengthStat, len(SnakeBodyParts))
+}
+
+//A food the autopilot can steer towards
+type FoodGoal struct {
+	Position Position
+	Value    float64
+	//Ticks until the food disappears, or -1 if it does not expire
+	TicksLeft int
+}
+
+//Returns the cells holding poison, which the autopilot steers around.
+func PoisonSet() map[Position]bool {
+	poison := make(map[Position]bool)
+	for _, f := range foods {
+		if foodKinds[f.foodType].growth < 0 {
+			poison[f.position] = true
+		}
+	}
+	return poison
+}
+
+//Lists the food worth eating, leaving out poison.
+func FoodGoals() []FoodGoal {
+	var goals []FoodGoal
+	for _, f := range foods {
+		kind := foodKinds[f.foodType]
+		if kind.growth < 0 {
+			continue
+		}
+		goals = append(goals, FoodGoal{f.position, kind.value, f.ticksLeft()})
+	}
+	return goals
 }
@@ -0,0 +1,286 @@
+package game
+
+//A block that moves back and forth in a straight line, turning around when something is in its way
+type patrol struct {
+	position  Position
+	direction Direction
+}
+
+//A snake moved by a simple AI that heads for the nearest food, or by an external bot. body[0] is its head.
+type enemy struct {
+	body      []Position
+	direction Direction
+	//Number of the bot steering the enemy, counting from 1, or 0 if the AI does
+	bot int
+}
+
+//An enemy waiting to come back after it was trapped
+type respawn struct {
+	ticksLeft int
+	bot       int
+}
+
+const (
+	//Cells around the player's head where no hazard is placed at the start of a run
+	hazardFreeRadius = 4
+	//Chance that an enemy takes a random turn instead of heading for the food
+	enemyWanderRate = 0.1
+)
+
+var (
+	PatrolCount = 0
+	EnemyCount  = 0
+	//Enemy snakes steered by external bots instead of the AI, on top of EnemyCount
+	BotCount = 0
+	//Ticks between two moves of a patrolling block
+	PatrolInterval = 2
+	//Ticks until a trapped enemy is replaced by a new one
+	EnemyRespawnTicks = 50
+	patrols           []patrol
+	enemies           []enemy
+	//The enemies that are missing and the ticks until they respawn
+	enemyRespawns []respawn
+	//The direction each bot asked for last, by bot number
+	botDirections = make(map[int]Direction)
+)
+
+//Places the patrolling blocks and enemy snakes at random positions away from the player's head.
+func resetHazards(positionMatrix [][]Position) {
+	patrols = nil
+	enemies = nil
+	enemyRespawns = nil
+	botDirections = make(map[int]Direction)
+	for i := 0; i < PatrolCount; i++ {
+		if position, found := tryGetHazardPosition(positionMatrix); found {
+			patrols = append(patrols, patrol{position, Direction(r.Intn(4))})
+		}
+	}
+	for i := 0; i < EnemyCount; i++ {
+		spawnEnemy(positionMatrix, 0)
+	}
+	for bot := 1; bot <= BotCount; bot++ {
+		spawnEnemy(positionMatrix, bot)
+	}
+}
+
+func spawnEnemy(positionMatrix [][]Position, bot int) {
+	if position, found := tryGetHazardPosition(positionMatrix); found {
+		enemies = append(enemies, enemy{[]Position{position}, Direction(r.Intn(4)), bot})
+	} else {
+		enemyRespawns = append(enemyRespawns, respawn{EnemyRespawnTicks, bot})
+	}
+}
+
+//Steers snake 0, the player's, or the enemy steered by the bot with that number.
+func Steer(snake int, direction Direction) {
+	if snake == 0 {
+		QueueDirection(direction)
+		return
+	}
+	SteerBot(snake, direction)
+}
+
+//Turns the enemy steered by bot on its next move. Bots that are not playing are ignored.
+func SteerBot(bot int, direction Direction) {
+	if bot >= 1 && bot <= BotCount {
+		botDirections[bot] = direction
+	}
+}
+
+func tryGetHazardPosition(positionMatrix [][]Position) (Position, bool) {
+	occupied := make(map[Position]bool)
+	for col := -hazardFreeRadius; col <= hazardFreeRadius; col++ {
+		for row := -hazardFreeRadius; row <= hazardFreeRadius; row++ {
+			x0, y0 := snakeHead.position.X0+col*DeltaX, snakeHead.position.Y0+row*DeltaY
+			occupied[Position{x0, y0, x0 + DeltaX, y0 + DeltaY}] = true
+		}
+	}
+	return tryGetFreePositionExcluding(positionMatrix, occupied)
+}
+
+//Cells taken by the patrolling blocks and the enemy snakes.
+func hazardCells() map[Position]bool {
+	cells := make(map[Position]bool)
+	for _, p := range patrols {
+		cells[p.position] = true
+	}
+	for _, e := range enemies {
+		for _, position := range e.body {
+			cells[position] = true
+		}
+	}
+	return cells
+}
+
+func IsHazard(position Position) bool {
+	return hazardCells()[position]
+}
+
+//HazardSet returns the cells the hazards take up and the cells they can move into by the next tick,
+//for the autopilot to steer around.
+func HazardSet() map[Position]bool {
+	cells := hazardCells()
+	for _, p := range patrols {
+		cells[getPositionOfNextMove(p.direction, p.position, true)] = true
+	}
+	for _, e := range enemies {
+		for _, direction := range getValidDirections(e.direction) {
+			cells[getPositionOfNextMove(direction, e.body[0], true)] = true
+		}
+	}
+	return cells
+}
+
+//Moves the patrolling blocks and the enemy snakes and respawns enemies that were trapped, unless they are
+//out of the battle royale. Called once per tick,
+//after the player's snake has moved. Hazards never move into the player's snake, they only kill a head that runs into them.
+func UpdateHazards(positionMatrix [][]Position) {
+	if Tick%PatrolInterval == 0 {
+		for i := range patrols {
+			movePatrol(&patrols[i], positionMatrix)
+		}
+	}
+
+	remaining := enemies[:0]
+	for i := range enemies {
+		if moveEnemy(&enemies[i], positionMatrix) {
+			remaining = append(remaining, enemies[i])
+		} else if !BattleRoyale {
+			enemyRespawns = append(enemyRespawns, respawn{EnemyRespawnTicks, enemies[i].bot})
+		}
+	}
+	enemies = remaining
+
+	respawns := enemyRespawns
+	enemyRespawns = nil
+	for _, waiting := range respawns {
+		if waiting.ticksLeft <= 1 {
+			spawnEnemy(positionMatrix, waiting.bot)
+		} else {
+			enemyRespawns = append(enemyRespawns, respawn{waiting.ticksLeft - 1, waiting.bot})
+		}
+	}
+}
+
+//Whether a hazard can move into position: it has to be on the board and free of walls, portals, snakes,
+//other hazards and, unless canEat is set, food.
+func hazardCanEnter(position Position, positionMatrix [][]Position, canEat bool) bool {
+	if !cellOnBoard(CellOf(position), positionMatrix) || arenaClosed(position) {
+		return false
+	}
+	if IsWall(position) || IsPortal(position) || GetsnakePositionSet(SnakeBodyParts)[position] || hazardCells()[position] {
+		return false
+	}
+	if index := foodIndexAt(position); index >= 0 {
+		return canEat && foodKinds[foods[index].foodType].growth > 0
+	}
+	return powerUpIndexAt(position) < 0
+}
+
+func movePatrol(p *patrol, positionMatrix [][]Position) {
+	for _, direction := range []Direction{p.direction, GetOppositeDirection(p.direction)} {
+		next := getPositionOfNextMove(direction, p.position, true)
+		if hazardCanEnter(next, positionMatrix, false) {
+			p.position = next
+			p.direction = direction
+			return
+		}
+	}
+}
+
+//Moves the enemy one cell towards the nearest food, or where its bot steered it, eating the food it gets to.
+//Returns false if the enemy is trapped, or its bot steered it into something, and it has to be removed.
+func moveEnemy(e *enemy, positionMatrix [][]Position) bool {
+	var moves []Direction
+	for _, direction := range getValidDirections(e.direction) {
+		if hazardCanEnter(getPositionOfNextMove(direction, e.body[0], true), positionMatrix, true) {
+			moves = append(moves, direction)
+		}
+	}
+	if len(moves) == 0 {
+		return false
+	}
+
+	direction := moves[r.Intn(len(moves))]
+	if e.bot > 0 {
+		//Like the player's snake, a bot's snake keeps going until it is turned
+		direction = e.direction
+		if steered, exist := botDirections[e.bot]; exist && steered != GetOppositeDirection(e.direction) {
+			direction = steered
+		}
+		if !hasDirection(moves, direction) {
+			return false
+		}
+	} else if target, found := nearestFood(e.body[0]); found && r.Float64() >= enemyWanderRate {
+		best := -1
+		for _, move := range moves {
+			if distance := cellDistance(getPositionOfNextMove(move, e.body[0], true), target); best < 0 || distance < best {
+				best = distance
+				direction = move
+			}
+		}
+	}
+
+	head := getPositionOfNextMove(direction, e.body[0], true)
+	e.direction = direction
+	if index := foodIndexAt(head); index >= 0 {
+		enemyEatFood(positionMatrix, index)
+		e.body = append([]Position{head}, e.body...)
+	} else {
+		e.body = append([]Position{head}, e.body[:len(e.body)-1]...)
+	}
+	return true
+}
+
+func hasDirection(directions []Direction, direction Direction) bool {
+	for _, d := range directions {
+		if d == direction {
+			return true
+		}
+	}
+	return false
+}
+
+func nearestFood(position Position) (Position, bool) {
+	best, found := Position{}, false
+	for _, f := range foods {
+		if foodKinds[f.foodType].growth <= 0 {
+			continue
+		}
+		if !found || cellDistance(position, f.position) < cellDistance(position, best) {
+			best, found = f.position, true
+		}
+	}
+	return best, found
+}
+
+//Removes the food an enemy ate, replacing the regular food so the player always has one to go for.
+func enemyEatFood(positionMatrix [][]Position, index int) {
+	eaten := foods[index]
+	foods = append(foods[:index], foods[index+1:]...)
+	if eaten.foodType != FoodTypes.Regular {
+		return
+	}
+	if position, found := tryGetFreePosition(positionMatrix); found {
+		foods = append(foods, food{FoodTypes.Regular, position, Tick})
+	}
+}
+
+func cellDistance(from Position, to Position) int {
+	dx, dy := (to.X0-from.X0)/DeltaX, (to.Y0-from.Y0)/DeltaY
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+func copyEnemies(source []enemy) []enemy {
+	copied := make([]enemy, len(source))
+	for i, e := range source {
+		copied[i] = enemy{append([]Position{}, e.body...), e.direction, e.bot}
+	}
+	return copied
+}
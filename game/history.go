@@ -0,0 +1,140 @@
+package game
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+	"time"
+)
+
+const historyCapacity = 1000
+
+type snapshot struct {
+	tick          int
+	bodyParts     []snakeBodyPart
+	headDirection Direction
+	foods         []food
+	score         int
+	streak        int
+	powerUps      []powerUp
+	activeEffects map[PowerUpType]int
+	goldenEaten   int
+	foodEaten     int
+	lastFoodTick  int
+	survived      time.Duration
+	patrols       []patrol
+	enemies       []enemy
+	enemyRespawns []respawn
+	arenaRing     int
+}
+
+//Ring buffer of past game states, overwriting the oldest snapshot when full.
+type history struct {
+	snapshots []snapshot
+	start     int
+	size      int
+}
+
+var (
+	Tick        = 0
+	StepMode    = false
+	tickHistory = newHistory(historyCapacity)
+)
+
+func newHistory(capacity int) *history {
+	return &history{snapshots: make([]snapshot, capacity)}
+}
+
+func (h *history) push(s snapshot) {
+	index := (h.start + h.size) % len(h.snapshots)
+	h.snapshots[index] = s
+	if h.size < len(h.snapshots) {
+		h.size++
+	} else {
+		h.start = (h.start + 1) % len(h.snapshots)
+	}
+}
+
+func (h *history) pop() (snapshot, bool) {
+	if h.size == 0 {
+		return snapshot{}, false
+	}
+	h.size--
+	index := (h.start + h.size) % len(h.snapshots)
+	return h.snapshots[index], true
+}
+
+func (h *history) clear() {
+	h.start = 0
+	h.size = 0
+}
+
+func takeSnapshot() snapshot {
+	bodyParts := make([]snakeBodyPart, len(SnakeBodyParts))
+	for i, bodyPart := range SnakeBodyParts {
+		bodyParts[i] = *bodyPart
+	}
+	return snapshot{Tick, bodyParts, headDirection, append([]food{}, foods...), view.ScoreStat.Value, streak,
+		append([]powerUp{}, powerUps...), copyEffects(activeEffects), goldenEaten, foodEaten, lastFoodTick, survived,
+		append([]patrol{}, patrols...), copyEnemies(enemies), append([]respawn{}, enemyRespawns...), arenaRing}
+}
+
+//Stores the current state so it can be restored by RewindTick, and advances the tick counter.
+func RecordTick() {
+	tickHistory.push(takeSnapshot())
+	Tick++
+}
+
+//Restores the state from before the last tick, returning false if there is no history left.
+func RewindTick(gui *gocui.Gui) (bool, error) {
+	previous, exist := tickHistory.pop()
+	if !exist {
+		return false, nil
+	}
+	if err := restoreSnapshot(gui, previous); err != nil {
+		return false, err
+	}
+	//A stepped back run is rewound too, so it is left out of the high score table
+	return true, view.UpdateStat(&view.RewindStat, view.RewindStat.Value+1)
+}
+
+func restoreSnapshot(gui *gocui.Gui, s snapshot) error {
+	*snakeHead = s.bodyParts[0]
+	bodyParts := []*snakeBodyPart{snakeHead}
+	for i := 1; i < len(s.bodyParts); i++ {
+		bodyPart := s.bodyParts[i]
+		bodyParts = append(bodyParts, &bodyPart)
+	}
+	SnakeBodyParts = bodyParts
+
+	Tick = s.tick
+	headDirection = s.headDirection
+	clearDirectionQueue()
+	foods = append([]food{}, s.foods...)
+	streak = s.streak
+	powerUps = append([]powerUp{}, s.powerUps...)
+	activeEffects = copyEffects(s.activeEffects)
+	goldenEaten = s.goldenEaten
+	foodEaten = s.foodEaten
+	lastFoodTick = s.lastFoodTick
+	survived = s.survived
+	patrols = append([]patrol{}, s.patrols...)
+	enemies = copyEnemies(s.enemies)
+	enemyRespawns = append([]respawn{}, s.enemyRespawns...)
+	arenaRing = s.arenaRing
+	if err := view.UpdateGoal(goalProgress()); err != nil {
+		return err
+	}
+	if err := view.UpdateEffects(effectsText()); err != nil {
+		return err
+	}
+	if err := view.UpdateStat(&view.LengthStat, len(SnakeBodyParts)); err != nil {
+		return err
+	}
+	if err := view.UpdateStat(&view.ScoreStat, s.score); err != nil {
+		return err
+	}
+	if err := UpdateStats(); err != nil {
+		return err
+	}
+	return DrawBoard(gui)
+}
@@ -0,0 +1,35 @@
+package game
+
+var (
+	InputQueueDepth = 3
+	directionQueue  []Direction
+)
+
+//Queues a turn so that several turns within one tick are applied on consecutive ticks.
+//Each turn is validated against the direction that will be in effect when it is applied.
+func QueueDirection(direction Direction) {
+	if len(directionQueue) >= InputQueueDepth {
+		return
+	}
+	effectiveDirection := snakeHead.currentDirection
+	if len(directionQueue) > 0 {
+		effectiveDirection = directionQueue[len(directionQueue)-1]
+	}
+	if direction == effectiveDirection || direction == GetOppositeDirection(effectiveDirection) {
+		return
+	}
+	directionQueue = append(directionQueue, direction)
+}
+
+//Applies the next queued turn, at most one per tick.
+func ApplyQueuedDirection() {
+	if len(directionQueue) == 0 {
+		return
+	}
+	headDirection = directionQueue[0]
+	directionQueue = directionQueue[1:]
+}
+
+func clearDirectionQueue() {
+	directionQueue = nil
+}
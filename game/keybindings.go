@@ -4,23 +4,28 @@ import (
 	"fmt"
 	"github.com/awesome-gocui/gocui"
 	snakeView "github.com/eiba/snake/game/view"
-	"time"
 )
 
 func initKeybindingsView(gui *gocui.Gui, gameView snakeView.Properties) error {
-	maxX  := gameView.Position.X1
-	if v, err := gui.SetView("keybindings", maxX+1, 0, maxX+26, 8, 0); err != nil {
+	maxX := gameView.Position.X1
+	lines := keybindingLines()
+	//A terminal too short for the whole side panel only lists the help key, the help modal lists the others
+	if _, terminalHeight := gui.Size(); len(lines)+2+snakeView.SidePanelHeight() > terminalHeight {
+		lines = []string{fmt.Sprint(actionLabel(ActionHelp), ": all keys")}
+	}
+	maxY := len(lines) + 1
+	snakeView.SidePanelOffset = maxY + 1
+	v, err := gui.SetView("keybindings", maxX+1, 0, maxX+snakeView.SidePanelWidth+1, maxY, 0)
+	if err != nil {
 		if !gocui.IsUnknownView(err) {
 			return err
 		}
 		v.Title = "Keybindings"
-		fmt.Fprintln(v, "Space: Restart")
-		fmt.Fprintln(v, "← ↑ → ↓: Move")
-		fmt.Fprintln(v, "W: Speed up")
-		fmt.Fprintln(v, "S: Slow down")
-		fmt.Fprintln(v, "P: Pause")
-		fmt.Fprintln(v, "A: Toggle autopilot")
-		fmt.Fprintln(v, "Esc: Exit")
+	}
+	//Drawn on every layout, as resizing the terminal can switch between the full and the short list
+	v.Clear()
+	for _, line := range lines {
+		fmt.Fprintln(v, line)
 	}
 	return nil
 }
@@ -29,7 +34,7 @@ func initKeybindingsView(gui *gocui.Gui, gameView snakeView.Properties) error {
 	if err := initQuitKey(gui); err != nil {
 		return err
 	}
-	if err := initSpaceKey(gui,snakeBodyParts, positionMatrix); err != nil {
+	if err := initSpaceKey(gui, positionMatrix); err != nil {
 		return err
 	}
 	if err := initMovementKeys(gui); err != nil {
@@ -38,10 +43,7 @@ func initKeybindingsView(gui *gocui.Gui, gameView snakeView.Properties) error {
 	if err := initTabKey(gui, snakeBodyParts); err != nil {
 		return err
 	}
-	if err := initSpeedKeys(gui, tickInterval); err != nil {
-		return err
-	}
-	if err := initPauseKey(gui, gameFinished, running); err != nil {
+	if err := initSpeedKeys(gui); err != nil {
 		return err
 	}
 	if err := initAutoPilotKey(gui, autoPilotEnabled); err != nil {
@@ -51,9 +53,9 @@ func initKeybindingsView(gui *gocui.Gui, gameView snakeView.Properties) error {
 }*/
 
 func initQuitKey(gui *gocui.Gui) error {
-	if err := gui.SetKeybinding("", gocui.KeyEsc, gocui.ModNone,
+	if err := SetActionKeybinding(gui, ActionQuit,
 		func(gui *gocui.Gui, view *gocui.View) error {
-			return gocui.ErrQuit
+			return confirmQuit(gui)
 		}); err != nil {
 		return err
 	}
@@ -61,28 +63,25 @@ func initQuitKey(gui *gocui.Gui) error {
 }
 
 func initMovementKeys(gui *gocui.Gui) error {
-	if err := initMovementKey(gui, gocui.KeyArrowUp, Directions.Up); err != nil {
+	if err := initMovementKey(gui, ActionUp, Directions.Up); err != nil {
 		return err
 	}
-	if err := initMovementKey(gui, gocui.KeyArrowRight, Directions.Right); err != nil {
+	if err := initMovementKey(gui, ActionRight, Directions.Right); err != nil {
 		return err
 	}
-	if err := initMovementKey(gui, gocui.KeyArrowDown, Directions.Down); err != nil {
+	if err := initMovementKey(gui, ActionDown, Directions.Down); err != nil {
 		return err
 	}
-	if err := initMovementKey(gui, gocui.KeyArrowLeft, Directions.Left); err != nil {
+	if err := initMovementKey(gui, ActionLeft, Directions.Left); err != nil {
 		return err
 	}
 	return nil
 }
 
-func initMovementKey(gui *gocui.Gui, key gocui.Key, keyDirection Direction) error {
-	if err := gui.SetKeybinding("", key, gocui.ModNone,
+func initMovementKey(gui *gocui.Gui, action Action, keyDirection Direction) error {
+	if err := SetActionKeybinding(gui, action,
 		func(gui *gocui.Gui, view *gocui.View) error {
-			if snakeHead.currentDirection == GetOppositeDirection(keyDirection) {
-				return nil
-			}
-			headDirection = keyDirection
+			QueueDirection(keyDirection)
 			return nil
 		}); err != nil {
 		return err
@@ -91,7 +90,7 @@ func initMovementKey(gui *gocui.Gui, key gocui.Key, keyDirection Direction) erro
 }
 
 func initTabKey(gui *gocui.Gui, snakeBodyParts []*snakeBodyPart) error {
-	if err := gui.SetKeybinding("", gocui.KeyTab, gocui.ModNone,
+	if err := SetActionKeybinding(gui, ActionGrow,
 		func(gui *gocui.Gui, view *gocui.View) error {
 			err := addBodyPartToEnd(*snakeBodyParts[len(snakeBodyParts)-1])
 			if err != nil {
@@ -104,52 +103,52 @@ func initTabKey(gui *gocui.Gui, snakeBodyParts []*snakeBodyPart) error {
 	return nil
 }
 
-func initSpaceKey(gui *gocui.Gui, snakeBodyParts []*snakeBodyPart, positionMatrix [][]Position) error {
-	if err := gui.SetKeybinding("", gocui.KeySpace, gocui.ModNone,
+func initSpaceKey(gui *gocui.Gui, positionMatrix [][]Position) error {
+	initGameOverKeys(positionMatrix)
+	if err := SetActionKeybinding(gui, ActionRestart,
 		func(gui *gocui.Gui, view *gocui.View) error {
-			return reset(gui, snakeBodyParts, positionMatrix)
+			return reset(gui, positionMatrix, 0)
 		}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func initSpeedKeys(gui *gocui.Gui, tickInterval *time.Duration) error {
-	if err := initSpeedKey(gui, 'w', tickInterval, -10); err != nil {
+func initSpeedKeys(gui *gocui.Gui) error {
+	if err := initSpeedKey(gui, ActionSpeedUp, true); err != nil {
 		return err
 	}
-	if err := initSpeedKey(gui, 's', tickInterval, 10); err != nil {
+	if err := initSpeedKey(gui, ActionSlowDown, false); err != nil {
 		return err
 	}
 	return nil
 }
 
-func initSpeedKey(gui *gocui.Gui, key rune, tickInterval *time.Duration, speedChange time.Duration) error {
-	if err := gui.SetKeybinding("", key, gocui.ModNone,
+func initSpeedKey(gui *gocui.Gui, action Action, faster bool) error {
+	if err := SetActionKeybinding(gui, action,
 		func(gui *gocui.Gui, view *gocui.View) error {
-			*tickInterval += speedChange * time.Millisecond
-			if *tickInterval < time.Millisecond {
-				*tickInterval = time.Millisecond
-			}
-			return nil
+			AdjustSpeed(faster)
+			TickInterval = CurveTickInterval()
+			return updateSpeedStat()
 		}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func initPauseKey(gui *gocui.Gui, gameFinished bool, running bool) (error, bool) {
-	if err := gui.SetKeybinding("", 'p', gocui.ModNone,
+//Binds the pause key to opening the pause modal, which closes itself on the same key.
+func InitPauseKey(gui *gocui.Gui, gameFinished *bool) error {
+	if err := SetActionKeybinding(gui, ActionPause,
 		func(gui *gocui.Gui, view *gocui.View) error {
-			return snakeView.Pause(gui, gameFinished, running)
+			return openPause(gui, gameFinished)
 		}); err != nil {
-		return err, false
+		return err
 	}
-	return nil, !running
+	return nil
 }
 
 func initAutoPilotKey(gui *gocui.Gui, autoPilotEnabled bool) (error, bool) {
-	if err := gui.SetKeybinding("", 'a', gocui.ModNone,
+	if err := SetActionKeybinding(gui, ActionAutopilot,
 		func(gui *gocui.Gui, view *gocui.View) error {
 			autoPilotEnabled = !autoPilotEnabled
 			return nil
@@ -157,4 +156,42 @@ func initAutoPilotKey(gui *gocui.Gui, autoPilotEnabled bool) (error, bool) {
 		return err, false
 	}
 	return nil, autoPilotEnabled
-}
\ No newline at end of file
+}
+
+func InitHelpKey(gui *gocui.Gui) error {
+	if err := SetActionKeybinding(gui, ActionHelp,
+		func(gui *gocui.Gui, view *gocui.View) error {
+			return openHelp(gui)
+		}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func InitStepKeys(gui *gocui.Gui, tick func(gui *gocui.Gui) error) error {
+	if err := SetActionKeybinding(gui, ActionStepMode,
+		func(gui *gocui.Gui, view *gocui.View) error {
+			StepMode = !StepMode
+			return snakeView.UpdateStepView(Tick, "-", StepMode)
+		}); err != nil {
+		return err
+	}
+	if err := SetActionKeybinding(gui, ActionStepNext,
+		func(gui *gocui.Gui, view *gocui.View) error {
+			StepMode = true
+			return tick(gui)
+		}); err != nil {
+		return err
+	}
+	if err := SetActionKeybinding(gui, ActionStepBack,
+		func(gui *gocui.Gui, view *gocui.View) error {
+			StepMode = true
+			if _, err := RewindTick(gui); err != nil {
+				return err
+			}
+			return snakeView.UpdateStepView(Tick, "rewind", StepMode)
+		}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package game
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+	"sort"
+	"strings"
+)
+
+type Action string
+
+const (
+	ActionRestart    Action = "restart"
+	ActionUp         Action = "up"
+	ActionRight      Action = "right"
+	ActionDown       Action = "down"
+	ActionLeft       Action = "left"
+	ActionSpeedUp    Action = "speed-up"
+	ActionSlowDown   Action = "slow-down"
+	ActionPause      Action = "pause"
+	ActionAutopilot  Action = "autopilot"
+	ActionOverlay    Action = "overlay"
+	ActionStepMode   Action = "step-mode"
+	ActionStepNext   Action = "step-next"
+	ActionStepBack   Action = "step-back"
+	ActionCasualMode Action = "casual-mode"
+	ActionRewind     Action = "rewind"
+	ActionGrow       Action = "grow"
+	ActionHelp       Action = "help"
+	ActionQuit       Action = "quit"
+	ActionSameSeed   Action = "same-seed"
+	ActionSaveReplay Action = "save-replay"
+	ActionHighScores Action = "leaderboard"
+)
+
+const defaultPresetName = "arrows"
+
+//Actions in the order they are listed in the keybindings view, together with their description
+var actionDescriptions = []struct {
+	action      Action
+	description string
+}{
+	{ActionRestart, "Restart"},
+	{ActionUp, "Up"},
+	{ActionRight, "Right"},
+	{ActionDown, "Down"},
+	{ActionLeft, "Left"},
+	{ActionSpeedUp, "Speed up"},
+	{ActionSlowDown, "Slow down"},
+	{ActionPause, "Pause"},
+	{ActionAutopilot, "Toggle autopilot"},
+	{ActionOverlay, "Toggle path overlay"},
+	{ActionStepMode, "Toggle step mode"},
+	{ActionStepNext, "Next tick"},
+	{ActionStepBack, "Previous tick"},
+	{ActionCasualMode, "Toggle casual mode"},
+	{ActionRewind, "Rewind (casual mode)"},
+	{ActionGrow, "Grow"},
+	{ActionHelp, "Help"},
+	{ActionQuit, "Exit"},
+	{ActionSameSeed, "Same seed (game over)"},
+	{ActionSaveReplay, "Save replay (game over)"},
+	{ActionHighScores, "Leaderboard (game over)"},
+}
+
+var (
+	keyNames = map[string]gocui.Key{
+		"Space":      gocui.KeySpace,
+		"Esc":        gocui.KeyEsc,
+		"Tab":        gocui.KeyTab,
+		"Enter":      gocui.KeyEnter,
+		"Backspace":  gocui.KeyBackspace2,
+		"ArrowUp":    gocui.KeyArrowUp,
+		"ArrowRight": gocui.KeyArrowRight,
+		"ArrowDown":  gocui.KeyArrowDown,
+		"ArrowLeft":  gocui.KeyArrowLeft,
+		"Ctrl+S":     gocui.KeyCtrlS,
+		"Ctrl+L":     gocui.KeyCtrlL,
+	}
+	keyLabels = map[string]string{
+		"ArrowUp":    "↑",
+		"ArrowRight": "→",
+		"ArrowDown":  "↓",
+		"ArrowLeft":  "←",
+	}
+	presets = map[string]map[Action][]string{
+		defaultPresetName: defaultKeyMap(),
+		"vim": withBindings(defaultKeyMap(), map[Action][]string{
+			ActionUp:    {"k", "ArrowUp"},
+			ActionRight: {"l", "ArrowRight"},
+			ActionDown:  {"j", "ArrowDown"},
+			ActionLeft:  {"h", "ArrowLeft"},
+		}),
+		"wasd": withBindings(defaultKeyMap(), map[Action][]string{
+			ActionUp:        {"w", "ArrowUp"},
+			ActionRight:     {"d", "ArrowRight"},
+			ActionDown:      {"s", "ArrowDown"},
+			ActionLeft:      {"a", "ArrowLeft"},
+			ActionSpeedUp:   {"+"},
+			ActionSlowDown:  {"-"},
+			ActionAutopilot: {"i"},
+			ActionOverlay:   {"v"},
+		}),
+	}
+	KeyMap = defaultKeyMap()
+)
+
+//Keys the quit confirmation is answered with, which the quit action cannot be bound to
+var confirmKeys = []string{"y", "n"}
+
+func defaultKeyMap() map[Action][]string {
+	return map[Action][]string{
+		ActionRestart:    {"Space"},
+		ActionUp:         {"ArrowUp"},
+		ActionRight:      {"ArrowRight"},
+		ActionDown:       {"ArrowDown"},
+		ActionLeft:       {"ArrowLeft"},
+		ActionSpeedUp:    {"w"},
+		ActionSlowDown:   {"s"},
+		ActionPause:      {"p"},
+		ActionAutopilot:  {"a"},
+		ActionOverlay:    {"d"},
+		ActionStepMode:   {"t"},
+		ActionStepNext:   {"n"},
+		ActionStepBack:   {"b"},
+		ActionCasualMode: {"c"},
+		ActionRewind:     {"r"},
+		ActionGrow:       {"Tab"},
+		ActionHelp:       {"?"},
+		ActionQuit:       {"Esc"},
+		ActionSameSeed:   {"Enter"},
+		ActionSaveReplay: {"Ctrl+S"},
+		ActionHighScores: {"Ctrl+L"},
+	}
+}
+
+func withBindings(keyMap map[Action][]string, bindings map[Action][]string) map[Action][]string {
+	for action, keys := range bindings {
+		keyMap[action] = keys
+	}
+	return keyMap
+}
+
+//Activates the given preset, with bindings overriding the bindings of the preset.
+func UseKeyMap(presetName string, bindings map[string][]string) error {
+	actionBindings := make(map[Action][]string)
+	for action, keys := range bindings {
+		actionBindings[Action(action)] = keys
+	}
+	keyMap, err := buildKeyMap(presetName, actionBindings)
+	if err != nil {
+		return fmt.Errorf("keybindings: %v", err)
+	}
+	KeyMap = keyMap
+	return nil
+}
+
+func buildKeyMap(presetName string, bindings map[Action][]string) (map[Action][]string, error) {
+	if presetName == "" {
+		presetName = defaultPresetName
+	}
+	preset, exist := presets[presetName]
+	if !exist {
+		return nil, fmt.Errorf("unknown keybindings preset %q", presetName)
+	}
+
+	keyMap := withBindings(copyKeyMap(preset), bindings)
+	if err := validateKeyMap(keyMap); err != nil {
+		return nil, err
+	}
+	return keyMap, nil
+}
+
+func copyKeyMap(keyMap map[Action][]string) map[Action][]string {
+	keyMapCopy := make(map[Action][]string)
+	for action, keys := range keyMap {
+		keyMapCopy[action] = append([]string{}, keys...)
+	}
+	return keyMapCopy
+}
+
+//Checks that every action and key is known and that no key is bound to more than one action. The keys of the game
+//over screen are actions too, so they are checked against the keys of the game, as the screen would hide them.
+func validateKeyMap(keyMap map[Action][]string) error {
+	knownActions := make(map[Action]bool)
+	for _, actionDescription := range actionDescriptions {
+		knownActions[actionDescription.action] = true
+	}
+
+	boundActions := make(map[string]Action)
+	var conflicts []string
+	for action, keys := range keyMap {
+		if !knownActions[action] {
+			return fmt.Errorf("unknown action %q", action)
+		}
+		for _, key := range keys {
+			if _, err := parseKey(key); err != nil {
+				return err
+			}
+			if boundAction, exist := boundActions[key]; exist && boundAction != action {
+				conflicts = append(conflicts, fmt.Sprintf("%v is bound to both %v and %v", key, boundAction, action))
+			}
+			boundActions[key] = action
+		}
+	}
+	for _, key := range keyMap[ActionQuit] {
+		for _, confirmKey := range confirmKeys {
+			if key == confirmKey {
+				conflicts = append(conflicts, fmt.Sprintf("%v answers the quit confirmation and cannot be bound to %v", key, ActionQuit))
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("conflicting keybindings: %v", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+//Parses a key name from the keybindings file into a gocui.Key, or a rune for single characters.
+func parseKey(name string) (interface{}, error) {
+	if key, exist := keyNames[name]; exist {
+		return key, nil
+	}
+	if runes := []rune(name); len(runes) == 1 {
+		return runes[0], nil
+	}
+	return nil, fmt.Errorf("unknown key %q", name)
+}
+
+func keyLabel(name string) string {
+	if label, exist := keyLabels[name]; exist {
+		return label
+	}
+	if len([]rune(name)) == 1 {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+//Returns the keys mapped to action as gocui keys, e.g. for the keys of a modal.
+func actionKeys(action Action) []interface{} {
+	var keys []interface{}
+	for _, name := range KeyMap[action] {
+		if key, err := parseKey(name); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+//Returns the labels of the keys mapped to action joined by slashes, e.g. "K/↑".
+func actionLabel(action Action) string {
+	labels := make([]string, len(KeyMap[action]))
+	for i, key := range KeyMap[action] {
+		labels[i] = keyLabel(key)
+	}
+	return strings.Join(labels, "/")
+}
+
+//Binds handler to every key mapped to action. The handler is skipped while a modal that captures the input is on top.
+func SetActionKeybinding(gui *gocui.Gui, action Action, handler func(*gocui.Gui, *gocui.View) error) error {
+	for _, name := range KeyMap[action] {
+		key, err := parseKey(name)
+		if err != nil {
+			return err
+		}
+		if err := gui.SetKeybinding("", key, gocui.ModNone, func(gui *gocui.Gui, v *gocui.View) error {
+			if view.InputCaptured() {
+				return nil
+			}
+			return handler(gui, v)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Describes the active key map, one line per action, for the keybindings view.
+func keybindingLines() []string {
+	var lines []string
+	for _, actionDescription := range actionDescriptions {
+		if len(KeyMap[actionDescription.action]) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprint(actionLabel(actionDescription.action), ": ", actionDescription.description))
+	}
+	return lines
+}
+
+//Finds the action bound to the key with the given name.
+func ActionForKey(name string) (Action, bool) {
+	for action, keys := range KeyMap {
+		for _, key := range keys {
+			if key == name {
+				return action, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+	"github.com/eiba/snake/highscore"
+)
+
+const (
+	helpModalName        = "help"
+	pauseModalName       = "pause"
+	confirmQuitModalName = "confirmQuit"
+	leaderboardModalName = "leaderboard"
+)
+
+//Opens the help screen, which lists the keybindings and holds the game until it is closed.
+func openHelp(gui *gocui.Gui) error {
+	closeHelp := func(gui *gocui.Gui) error {
+		return view.CloseModal(gui, helpModalName)
+	}
+	keys := map[interface{}]func(gui *gocui.Gui) error{
+		gocui.KeyEsc:   closeHelp,
+		gocui.KeyEnter: closeHelp,
+	}
+	for _, key := range actionKeys(ActionHelp) {
+		keys[key] = closeHelp
+	}
+	return view.OpenModal(gui, &view.Modal{
+		Name:          helpModalName,
+		Title:         "Help",
+		Lines:         append(keybindingLines(), "", "Esc: close"),
+		Keys:          keys,
+		CapturesInput: true,
+		PausesGame:    true,
+	})
+}
+
+//Holds the snake until the pause key or Esc is pressed again. A finished game is not paused.
+func openPause(gui *gocui.Gui, gameFinished *bool) error {
+	if *gameFinished {
+		return nil
+	}
+	resume := func(gui *gocui.Gui) error {
+		return view.CloseModal(gui, pauseModalName)
+	}
+	keys := map[interface{}]func(gui *gocui.Gui) error{
+		gocui.KeyEsc: resume,
+	}
+	for _, key := range actionKeys(ActionPause) {
+		keys[key] = resume
+	}
+	return view.OpenModal(gui, &view.Modal{
+		Name:          pauseModalName,
+		Title:         "Pause",
+		Lines:         []string{fmt.Sprintf("%v/Esc: resume", actionLabel(ActionPause))},
+		Keys:          keys,
+		CapturesInput: true,
+		PausesGame:    true,
+	})
+}
+
+//Asks before quitting a run that is still going. Once the run is over the game quits right away.
+func confirmQuit(gui *gocui.Gui) error {
+	if runOver {
+		return gocui.ErrQuit
+	}
+	quit := func(gui *gocui.Gui) error {
+		return gocui.ErrQuit
+	}
+	keys := map[interface{}]func(gui *gocui.Gui) error{
+		'y': quit,
+		'n': func(gui *gocui.Gui) error {
+			return view.CloseModal(gui, confirmQuitModalName)
+		},
+	}
+	//Pressing the quit key again confirms. The key map cannot bind quitting to Y or N, see validateKeyMap.
+	for _, key := range actionKeys(ActionQuit) {
+		keys[key] = quit
+	}
+	return view.OpenModal(gui, &view.Modal{
+		Name:          confirmQuitModalName,
+		Title:         "Quit?",
+		Lines:         []string{fmt.Sprintf("%v/Y: quit, N: keep playing", actionLabel(ActionQuit))},
+		Keys:          keys,
+		CapturesInput: true,
+		PausesGame:    true,
+	})
+}
+
+//Shows the high score table over the game over modal until it is closed.
+func openLeaderboard(gui *gocui.Gui) error {
+	entries, err := highscore.Load()
+	if err != nil {
+		return err
+	}
+	lines := []string{}
+	for i, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%2d. %v", i+1, entry))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No runs yet")
+	}
+	closeLeaderboard := func(gui *gocui.Gui) error {
+		return view.CloseModal(gui, leaderboardModalName)
+	}
+	keys := map[interface{}]func(gui *gocui.Gui) error{
+		gocui.KeyEsc:   closeLeaderboard,
+		gocui.KeyEnter: closeLeaderboard,
+	}
+	for _, key := range actionKeys(ActionHighScores) {
+		keys[key] = closeLeaderboard
+	}
+	return view.OpenModal(gui, &view.Modal{
+		Name:          leaderboardModalName,
+		Title:         "Leaderboard",
+		Lines:         append(lines, "", "Esc: close"),
+		Keys:          keys,
+		CapturesInput: true,
+	})
+}
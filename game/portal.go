@@ -0,0 +1,65 @@
+package game
+
+//A portal on the board. Pair is the index of the stage's portal pair it belongs to.
+type PortalCell struct {
+	Cell
+	Pair int
+}
+
+var (
+	//Both cells of every portal pair, each mapped to its partner
+	portals     = make(map[Position]Position)
+	portalCells = []PortalCell{}
+)
+
+//Sets up the portal pairs of the stage, ignoring pairs with a cell outside the board.
+func setPortals(pairs [][2]Cell, positionMatrix [][]Position) {
+	portals = make(map[Position]Position)
+	portalCells = []PortalCell{}
+	for i, pair := range pairs {
+		if !cellOnBoard(pair[0], positionMatrix) || !cellOnBoard(pair[1], positionMatrix) {
+			continue
+		}
+		entry, exit := positionMatrix[pair[0].Col][pair[0].Row], positionMatrix[pair[1].Col][pair[1].Row]
+		portals[entry] = exit
+		portals[exit] = entry
+		portalCells = append(portalCells, PortalCell{pair[0], i}, PortalCell{pair[1], i})
+	}
+}
+
+//Brings a position that left the board back in on the opposite edge.
+func wrapPosition(position Position) Position {
+	if boardCols == 0 || boardRows == 0 {
+		return position
+	}
+	col := (position.X0/DeltaX + boardCols) % boardCols
+	row := (position.Y0/DeltaY + boardRows) % boardRows
+	return Position{col * DeltaX, row * DeltaY, (col + 1) * DeltaX, (row + 1) * DeltaY}
+}
+
+func cellOnBoard(cell Cell, positionMatrix [][]Position) bool {
+	return cell.Col >= 0 && cell.Col < len(positionMatrix) && cell.Row >= 0 && cell.Row < len(positionMatrix[cell.Col])
+}
+
+func IsPortal(position Position) bool {
+	_, exist := portals[position]
+	return exist
+}
+
+//Portals maps every portal cell to the cell it leads to.
+func Portals() map[Position]Position {
+	return portals
+}
+
+//Step returns where the head ends up moving one cell from position in direction. Moving into a portal puts the head
+//on its partner, still heading in the same direction, and in wrap mode moving off the board puts it on the opposite edge.
+func Step(position Position, direction Direction) Position {
+	next := getPositionOfNextMove(direction, position, true)
+	if Wrap {
+		next = wrapPosition(next)
+	}
+	if exit, exist := portals[next]; exist {
+		return exit
+	}
+	return next
+}
@@ -6,10 +6,10 @@ func initPositionMatrix(gameViewPosition Position, positionMatrix [][]Position)
 	if len(positionMatrix) == gameViewCols && len(positionMatrix[0]) == gameViewRows {
 		return positionMatrix
 	}
-	return generatePositionMatrix(gameViewPosition)
+	return GeneratePositionMatrix(gameViewPosition)
 }
 
-func generatePositionMatrix(gameViewPosition Position) [][]Position {
+func GeneratePositionMatrix(gameViewPosition Position) [][]Position {
 	totalCols := gameViewPosition.X1 / DeltaX
 	totalRows := gameViewPosition.Y1 / DeltaY
 	positionMatrix := make([][]Position, totalCols)
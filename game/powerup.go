@@ -0,0 +1,151 @@
+package game
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game/view"
+	"strings"
+	"time"
+)
+
+type PowerUpType int
+type powerUpTypes struct {
+	SlowMotion PowerUpType
+	Ghost      PowerUpType
+	Shrink     PowerUpType
+}
+
+type powerUp struct {
+	powerUpType PowerUpType
+	position    Position
+	spawnTick   int
+}
+
+var (
+	PowerUpTypes = powerUpTypes{0, 1, 2}
+	//Chance per tick that a power-up appears while there is none on the board
+	PowerUpSpawnRate = 0.005
+	//Ticks until a power-up that is not picked up disappears
+	PowerUpLifetime = 80
+	//Ticks the slow-motion and ghost effects last once picked up
+	PowerUpDuration = 50
+	//How many times slower the game runs in slow motion
+	SlowMotionFactor = 2
+	powerUpNames     = map[PowerUpType]string{
+		PowerUpTypes.SlowMotion: "slow-motion",
+		PowerUpTypes.Ghost:      "ghost",
+		PowerUpTypes.Shrink:     "shrink",
+	}
+	powerUps []powerUp
+	//Ticks left of the active effects
+	activeEffects = make(map[PowerUpType]int)
+	//Effects picked up during the current tick, which only start counting down on the next one
+	pickedUpEffects = make(map[PowerUpType]bool)
+)
+
+func (t PowerUpType) String() string {
+	return powerUpNames[t]
+}
+
+func effectActive(powerUpType PowerUpType) bool {
+	return activeEffects[powerUpType] > 0
+}
+
+//Reports whether the snake can pass through its own body.
+func GhostActive() bool {
+	return effectActive(PowerUpTypes.Ghost)
+}
+
+//Returns the time until the next tick, which is longer in slow motion.
+func NextTickInterval(tickInterval time.Duration) time.Duration {
+	if effectActive(PowerUpTypes.SlowMotion) {
+		return tickInterval * time.Duration(SlowMotionFactor)
+	}
+	return tickInterval
+}
+
+func powerUpIndexAt(position Position) int {
+	for i, p := range powerUps {
+		if p.position == position {
+			return i
+		}
+	}
+	return -1
+}
+
+//Counts down the active effects, removes power-ups that were not picked up in time and spawns new ones.
+//Called once per tick.
+func UpdatePowerUps(positionMatrix [][]Position) error {
+	for powerUpType, ticksLeft := range activeEffects {
+		if pickedUpEffects[powerUpType] {
+			continue
+		}
+		if ticksLeft <= 1 {
+			delete(activeEffects, powerUpType)
+		} else {
+			activeEffects[powerUpType] = ticksLeft - 1
+		}
+	}
+	pickedUpEffects = make(map[PowerUpType]bool)
+
+	remaining := powerUps[:0]
+	for _, p := range powerUps {
+		if Tick-p.spawnTick < PowerUpLifetime {
+			remaining = append(remaining, p)
+		}
+	}
+	powerUps = remaining
+
+	if len(powerUps) == 0 && r.Float64() < PowerUpSpawnRate {
+		if position, found := tryGetFreePosition(positionMatrix); found {
+			powerUps = append(powerUps, powerUp{PowerUpType(r.Intn(len(powerUpNames))), position, Tick})
+		}
+	}
+	if err := view.UpdateEffects(effectsText()); err != nil {
+		return err
+	}
+	return updateSpeedStat()
+}
+
+func pickUpPowerUp(index int) error {
+	picked := powerUps[index]
+	powerUps = append(powerUps[:index], powerUps[index+1:]...)
+	if picked.powerUpType == PowerUpTypes.Shrink {
+		//Removes a third of the snake
+		if err := shrink(len(SnakeBodyParts) / 3); err != nil {
+			return err
+		}
+	} else {
+		activeEffects[picked.powerUpType] = PowerUpDuration
+		pickedUpEffects[picked.powerUpType] = true
+	}
+	return view.UpdateEffects(effectsText())
+}
+
+//Lists the active effects with their countdowns, e.g. "ghost 12 slow-motion 30".
+func effectsText() string {
+	var effects []string
+	for _, powerUpType := range []PowerUpType{PowerUpTypes.SlowMotion, PowerUpTypes.Ghost} {
+		if ticksLeft := activeEffects[powerUpType]; ticksLeft > 0 {
+			effects = append(effects, fmt.Sprint(powerUpType, " ", ticksLeft))
+		}
+	}
+	if len(effects) == 0 {
+		return "-"
+	}
+	return strings.Join(effects, " ")
+}
+
+func copyEffects(effects map[PowerUpType]int) map[PowerUpType]int {
+	effectsCopy := make(map[PowerUpType]int)
+	for powerUpType, ticksLeft := range effects {
+		effectsCopy[powerUpType] = ticksLeft
+	}
+	return effectsCopy
+}
+
+func resetPowerUps() error {
+	powerUps = nil
+	activeEffects = make(map[PowerUpType]int)
+	pickedUpEffects = make(map[PowerUpType]bool)
+	return view.UpdateEffects(effectsText())
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+)
+
+//Layers drawn below the food and the snake, such as the autopilot overlay
+var BoardLayers []func(board *view.Board)
+
+//Draws the current state into the game board.
+func DrawBoard(gui *gocui.Gui) error {
+	board := view.GameBoard
+	cols, rows := board.Size()
+	return DrawState(gui, CurrentState(cols, rows))
+}
+
+//Composes the board from the layers, the walls, the closing border, the portals, the foods, the power-ups, the hazards
+//and the snakes in state, and draws the cells that changed.
+func DrawState(gui *gocui.Gui, state State) error {
+	board := view.GameBoard
+	board.Clear()
+	for _, layer := range BoardLayers {
+		layer(board)
+	}
+	wall := view.WallStyle()
+	for _, cell := range state.Walls {
+		board.SetCell(cell.Col, cell.Row, wall)
+	}
+	closed, warned := state.ArenaCells()
+	for _, cell := range closed {
+		board.SetCell(cell.Col, cell.Row, wall)
+	}
+	warning := view.WarningStyle()
+	for _, cell := range warned {
+		board.SetCell(cell.Col, cell.Row, warning)
+	}
+	portal := view.PortalStyle()
+	for _, p := range state.Portals {
+		board.SetCell(p.Col, p.Row, portal)
+	}
+	for _, f := range state.Foods {
+		board.SetCell(f.Col, f.Row, view.FoodStyle(f.Type))
+	}
+	for _, p := range state.PowerUps {
+		board.SetCell(p.Col, p.Row, view.PowerUpStyle(p.Type))
+	}
+	hazard := view.HazardStyle()
+	for _, cell := range state.Hazards {
+		board.SetCell(cell.Col, cell.Row, hazard)
+	}
+	for _, enemy := range state.Enemies {
+		for i := len(enemy) - 1; i >= 0; i-- {
+			board.SetCell(enemy[i].Col, enemy[i].Row, view.EnemyStyle(i))
+		}
+	}
+	for i := len(state.Snake) - 1; i >= 0; i-- {
+		board.SetCell(state.Snake[i].Col, state.Snake[i].Row, view.SnakeStyle(i, len(state.Snake)))
+	}
+	return board.Draw(gui)
+}
@@ -7,33 +7,60 @@ import (
 	"time"
 )
 
-var r = rand.New(rand.NewSource(time.Now().UnixNano()))
+var (
+	r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	//Called when a new run starts, e.g. to start recording a new replay
+	Restarted func()
+	//The seed of the current run's random numbers, so the run can be played again with the same food
+	runSeed int64
+)
+
+//Seeds the random numbers of a new run with seed, or with a new seed if it is 0.
+func Reseed(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()%1000000 + 1
+	}
+	runSeed = seed
+	r.Seed(seed)
+	view.Seed(seed)
+}
 
-func reset(gui *gocui.Gui, snakeBodyParts []*snakeBodyPart, positionMatrix [][]Position) error {
+//Starts a new run, playing the last run again if seed is the seed it was played with, or a new one if seed is 0.
+func reset(gui *gocui.Gui, positionMatrix [][]Position, seed int64) error {
 	//main.running = true
 
-	if err := deleteSnakeBody(gui, snakeBodyParts); err != nil {
+	if err := view.CloseModals(gui); err != nil {
 		return err
 	}
+	//main.gameFinished = false
 
-	var err error
-	snakeHead.position, err = view.SetViewAtRandomPosition(gui, snakeHead.viewName, positionMatrix, true)
-	if err != nil {
+	if err := startRun(positionMatrix, seed); err != nil {
 		return err
 	}
-	foodView.Position, err = view.SetViewAtRandomPosition(gui, foodView.Name, positionMatrix, false)
-	if err != nil {
+	return DrawBoard(gui)
+}
+
+//Puts the snake, the food, the stage and the stats back to where a run starts, with a new head of length 1.
+func startRun(positionMatrix [][]Position, seed int64) error {
+	if err := recordRun(); err != nil {
 		return err
 	}
-
+	advanceStage()
+	Reseed(seed)
+	runOver = false
+	runRecorded = false
+	deathCause = ""
+	replaySaved = ""
+	snakeHead.position = view.GetRandomPosition(positionMatrix)
 	headDirection = Direction(r.Intn(4))
 	snakeHead.currentDirection = headDirection
-	snakeBodyParts = []*snakeBodyPart{snakeHead}
+	//The dead snake's body is dropped before the food is placed, so the food can land where it lay
+	SnakeBodyParts = []*snakeBodyPart{snakeHead}
+	ResetFoods(positionMatrix)
 
-	//main.gameOverView.Visible = false
-	//main.pauseView.Visible = false
-	//main.loadingView.Visible = false
-	//main.gameFinished = false
+	tickHistory.clear()
+	clearDirectionQueue()
+	Tick = 0
 
 	//main.foodPath = []main.node{}
 	//main.pathIndex = -1
@@ -44,14 +71,30 @@ func reset(gui *gocui.Gui, snakeBodyParts []*snakeBodyPart, positionMatrix [][]P
 	if err := view.UpdateStat(&view.LengthStat, 1); err != nil {
 		return err
 	}
-	return nil
-}
-
-func deleteSnakeBody(gui *gocui.Gui, snakeBodyParts []*snakeBodyPart) error {
-	for i := 1; i < len(snakeBodyParts); i++ {
-		if err := gui.DeleteView(snakeBodyParts[i].viewName); err != nil && !gocui.IsUnknownView(err) {
-			return err
-		}
+	if err := view.UpdateStat(&view.RewindStat, 0); err != nil {
+		return err
+	}
+	if err := resetScore(); err != nil {
+		return err
+	}
+	if err := resetPowerUps(); err != nil {
+		return err
+	}
+	if err := StartStage(positionMatrix); err != nil {
+		return err
+	}
+	//Every run starts on the curve, so only runs that were sped up or slowed down by hand are flagged
+	speedOffset = 0
+	speedOverridden = false
+	TickInterval = CurveTickInterval()
+	if err := updateSpeedStat(); err != nil {
+		return err
+	}
+	if err := UpdateStats(); err != nil {
+		return err
+	}
+	if Restarted != nil {
+		Restarted()
 	}
 	return nil
 }
@@ -0,0 +1,43 @@
+package game
+
+import (
+	"github.com/eiba/snake/game/view"
+	"testing"
+)
+
+func TestStartRunResetsLengthAndSpeed(t *testing.T) {
+	positionMatrix := GeneratePositionMatrix(Position{X0: 0, Y0: 0, X1: 20 * DeltaX, Y1: 10 * DeltaY})
+	CurrentDifficulty = difficulties["normal"]
+	CurrentStage = nil
+	if err := startRun(positionMatrix, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	//A long snake that was sped up by hand, as a run that is restarted after dying
+	for i := 0; i < 10; i++ {
+		if err := addBodyPartToEnd(*SnakeBodyParts[len(SnakeBodyParts)-1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	AdjustSpeed(true)
+	TickInterval = CurveTickInterval()
+	if TickInterval == CurrentDifficulty.Start {
+		t.Fatalf("tick interval %v is still the start of the curve before the restart", TickInterval)
+	}
+
+	if err := startRun(positionMatrix, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(SnakeBodyParts) != 1 {
+		t.Errorf("snake has %d parts after the restart, want 1", len(SnakeBodyParts))
+	}
+	if view.LengthStat.Value != 1 {
+		t.Errorf("length stat is %d after the restart, want 1", view.LengthStat.Value)
+	}
+	if TickInterval != CurrentDifficulty.Start {
+		t.Errorf("tick interval is %v after the restart, want %v", TickInterval, CurrentDifficulty.Start)
+	}
+	if speedOverridden {
+		t.Error("restarted run is still flagged as sped up by hand")
+	}
+}
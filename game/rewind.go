@@ -0,0 +1,104 @@
+package game
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+	"github.com/eiba/snake/highscore"
+	"time"
+)
+
+var (
+	CasualMode    = false
+	RewindSeconds = 5
+	runOver       = false
+	runRecorded   = false
+	//What ended the run, shown in its summary. Empty if the run was won.
+	deathCause = ""
+)
+
+func InitRewindKeys(gui *gocui.Gui, gameFinished *bool, running *bool) error {
+	if err := SetActionKeybinding(gui, ActionCasualMode,
+		func(gui *gocui.Gui, v *gocui.View) error {
+			//Switching to casual mode after the game is over would allow rewinding a run that has already been lost
+			if runOver {
+				return nil
+			}
+			CasualMode = !CasualMode
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := SetActionKeybinding(gui, ActionRewind,
+		func(gui *gocui.Gui, v *gocui.View) error {
+			if !CasualMode || !*gameFinished {
+				return nil
+			}
+			ticks := int(time.Duration(RewindSeconds) * time.Second / TickInterval)
+			if err := rewind(gui, ticks); err != nil {
+				return err
+			}
+			*gameFinished = false
+			*running = true
+			return view.HideGameOver(gui)
+		}); err != nil {
+		return err
+	}
+	return nil
+}
+
+//Rewinds up to ticks ticks, stopping early if the history runs out.
+func rewind(gui *gocui.Gui, ticks int) error {
+	previous, exist := tickHistory.pop()
+	if !exist {
+		return nil
+	}
+	for i := 1; i < ticks; i++ {
+		older, exist := tickHistory.pop()
+		if !exist {
+			break
+		}
+		previous = older
+	}
+	if err := restoreSnapshot(gui, previous); err != nil {
+		return err
+	}
+	runOver = false
+	stageClear = false
+	return view.UpdateStat(&view.RewindStat, view.RewindStat.Value+1)
+}
+
+//Marks the run as over. Casual runs can still be rewound, so they are only recorded when the game is restarted.
+func endRun() error {
+	runOver = true
+	if err := summarize(); err != nil {
+		return err
+	}
+	if CasualMode {
+		return view.SetGameOverText(fmt.Sprint(actionLabel(ActionRestart), ": new seed, ", actionLabel(ActionRewind), ": rewind"), gameOverActions())
+	}
+	if err := view.SetGameOverText(fmt.Sprint(actionLabel(ActionRestart), ": new seed"), gameOverActions()); err != nil {
+		return err
+	}
+	return recordRun()
+}
+
+//Reports whether the snake has died or filled the board.
+func RunOver() bool {
+	return runOver
+}
+
+//Adds the run to the high score table. Rewound runs are left out, as they are not comparable to regular runs.
+func recordRun() error {
+	if !runOver || runRecorded || view.RewindStat.Value > 0 {
+		return nil
+	}
+	runRecorded = true
+	return highscore.Add(highscore.Entry{
+		Score:         view.ScoreStat.Value,
+		Length:        len(SnakeBodyParts),
+		Difficulty:    CurrentDifficulty.Name,
+		SpeedOverride: speedOverridden,
+		Date:          time.Now(),
+	})
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"github.com/eiba/snake/game/view"
+	"time"
+)
+
+const (
+	basePoints = 10
+	//Tick interval at which food is worth its base points, faster games are worth proportionally more
+	referenceTickInterval = 100 * time.Millisecond
+	maxMultiplier         = 5
+)
+
+var (
+	//Current time between two moves without power-up effects. The game loop sleeps for it, so it is the one place
+	//the speed of the game is kept.
+	TickInterval = 50 * time.Millisecond
+	//Consecutive quick eats, each adding one to the multiplier
+	streak = 0
+)
+
+//Adds the points for eating the food to the score. Food is worth more the faster the game runs
+//and up to twice as much the quicker it is picked up. Quick eats in a row raise the multiplier,
+//eating food that is worth nothing, such as poison, breaks the streak.
+func scoreFood(positionMatrix [][]Position, eaten food) error {
+	value := foodKinds[eaten.foodType].value
+	if value == 0 {
+		streak = 0
+		return nil
+	}
+	ticksTaken := Tick - eaten.spawnTick
+	quickTicks := len(positionMatrix)
+	if quickTicks > 0 {
+		quickTicks += len(positionMatrix[0])
+	}
+
+	quickness := 0.0
+	if ticksTaken < quickTicks {
+		quickness = 1 - float64(ticksTaken)/float64(quickTicks)
+		if streak < maxMultiplier-1 {
+			streak++
+		}
+	} else {
+		streak = 0
+	}
+	speed := float64(referenceTickInterval) / float64(NextTickInterval(TickInterval))
+	points := int(basePoints*value*speed*(1+quickness)) * Multiplier()
+	return view.UpdateStat(&view.ScoreStat, view.ScoreStat.Value+points)
+}
+
+func Multiplier() int {
+	return 1 + streak
+}
+
+func resetScore() error {
+	streak = 0
+	return view.UpdateStat(&view.ScoreStat, 0)
+}
@@ -1,14 +1,8 @@
 package game
 
-import (
-	"fmt"
-	"github.com/awesome-gocui/gocui"
-)
-
 type snakeBodyPart struct {
 	currentDirection  Direction
 	previousDirection Direction
-	viewName          string
 	position          Position
 }
 
@@ -27,7 +21,8 @@ type movementDirections struct {
 	Left  Direction
 }
 
-const (
+//Size of a board cell in terminal columns and rows
+var (
 	DeltaX = 2
 	DeltaY = 1
 )
@@ -35,32 +30,23 @@ const (
 var (
 	Directions     = movementDirections{0, 1, 2, 3}
 	headDirection  = Direction(main.r.Intn(4))
-	snakeHead      = &snakeBodyPart{headDirection, headDirection, "s0", Position{}}
+	snakeHead      = &snakeBodyPart{headDirection, headDirection, Position{}}
 	SnakeBodyParts = []*snakeBodyPart{snakeHead}
+	//Where the head was before its last move, which the first body part moves to
+	previousHeadPosition = Position{}
+	//Whether the snake leaves the board on one edge and comes back in on the opposite edge instead of dying
+	Wrap = false
 )
 
+//Adds a body part on top of the tail. It stays where the tail was while the rest of the snake moves on, so it lines up
+//behind the tail however the tail got there, e.g. through a portal or across the edge of the board.
 func addBodyPartToEnd(currentLastsnakeBodyPart snakeBodyPart) error {
-	offsetX, offsetY := calculateOffsets(currentLastsnakeBodyPart.currentDirection, false)
-
-	name := fmt.Sprintf("s%v", len(SnakeBodyParts))
-	position := Position{
-		currentLastsnakeBodyPart.position.x0 + offsetX,
-		currentLastsnakeBodyPart.position.y0 + offsetY,
-		currentLastsnakeBodyPart.position.x1 + offsetX,
-		currentLastsnakeBodyPart.position.y1 + offsetY,
-	}
-
-	_, err := main.gui.SetView(name, position.x0, position.y0, position.x1, position.y1, 0)
-	if err != nil && !gocui.IsUnknownView(err) {
-		return err
-	}
 	SnakeBodyParts = append(
 		SnakeBodyParts,
 		&snakeBodyPart{
 			currentLastsnakeBodyPart.currentDirection,
 			currentLastsnakeBodyPart.previousDirection,
-			name,
-			position,
+			currentLastsnakeBodyPart.position,
 		})
 	return main.updateStat(&main.lengthStat, main.lengthStat.value+1)
 }
@@ -84,28 +70,56 @@ func positionOverlap(position1 Position, position2 Position) bool {
 }
 
 func movesnakeHead() error {
-	err := moveHeadView(snakeHead)
-	if err != nil {
-		return err
-	}
+	moveHeadView(snakeHead)
 
 	if fatalCollision(snakeHead.position) {
+		deathCause = collisionCause(snakeHead.position)
+		if err := endRun(); err != nil {
+			return err
+		}
 		return main.gameOver("Game Over")
 	}
 
-	if positionOverlap(snakeHead.position, main.foodView.position) {
-		return main.eatFood()
+	if index := powerUpIndexAt(snakeHead.position); index >= 0 {
+		if err := pickUpPowerUp(index); err != nil {
+			return err
+		}
+	}
+	if index := foodIndexAt(snakeHead.position); index >= 0 {
+		return main.eatFood(index)
 	}
 	return nil
 }
 
+//The head passes through the body while the ghost effect is active, but never through walls or hazards.
 func fatalCollision(position Position) bool {
-	if mainViewCollision(position) || bodyCollision(position) {
+	if mainViewCollision(position) || IsWall(position) || IsHazard(position) || (!effectActive(PowerUpTypes.Ghost) && bodyCollision(position)) {
 		return true
 	}
 	return false
 }
 
+//Describes what the head ran into at position, for the summary of the run.
+func collisionCause(position Position) string {
+	switch {
+	case IsWall(position):
+		return "hit a wall"
+	case hazardCells()[position]:
+		for _, p := range patrols {
+			if p.position == position {
+				return "hit a hazard"
+			}
+		}
+		return "hit an enemy snake"
+	case mainViewCollision(position):
+		if arenaClosed(position) {
+			return "hit the closing border"
+		}
+		return "hit the edge of the board"
+	}
+	return "ran into itself"
+}
+
 func bodyCollision(position Position) bool {
 	for i := 1; i < len(SnakeBodyParts); i++ {
 		collision := positionOverlap(position, SnakeBodyParts[i].position)
@@ -120,57 +134,37 @@ func mainViewCollision(position Position) bool {
 	xG0, yG0, xG1, yG1 := main.gameView.position.x0, main.gameView.position.y0, main.gameView.position.x1, main.gameView.position.y1
 	xH0, yH0, xH1, yH1 := position.x0, position.y0, position.x1, position.y1
 
-	maxX, maxY, minX, minY := xG1-xG0, yG1-yG0, 0, 0
+	//The rings closed in battle royale mode are part of the border
+	maxX, maxY, minX, minY := xG1-xG0-arenaRing*DeltaX, yG1-yG0-arenaRing*DeltaY, arenaRing*DeltaX, arenaRing*DeltaY
 	if xH0 >= minX && yH0 >= minY && xH1 <= maxX && yH1 <= maxY {
 		return false
 	}
 	return true
 }
 
+//Moves every body part to where the part in front of it was, so the body follows the path of the head,
+//also through portals.
 func movesnakeBodyParts() error {
+	position := previousHeadPosition
 	for i := 1; i < len(SnakeBodyParts); i++ {
-		err := movesnakeBodyPart(SnakeBodyParts[i-1], SnakeBodyParts[i])
-		if err != nil {
-			return err
-		}
+		previousPosition := SnakeBodyParts[i].position
+		movesnakeBodyPart(SnakeBodyParts[i-1], SnakeBodyParts[i], position)
+		position = previousPosition
 	}
 	return nil
 }
 
-func movesnakeBodyPart(previoussnakeBodyPart *snakeBodyPart, currentsnakeBodyPart *snakeBodyPart) error {
-	currentsnakeBodyPart.position = getPositionOfNextMove(previoussnakeBodyPart.currentDirection, previoussnakeBodyPart.position, false)
-	_, err := main.gui.SetView(
-		currentsnakeBodyPart.viewName,
-		currentsnakeBodyPart.position.x0,
-		currentsnakeBodyPart.position.y0,
-		currentsnakeBodyPart.position.x1,
-		currentsnakeBodyPart.position.y1,
-		0)
-	if err != nil {
-		return err
-	}
-
+func movesnakeBodyPart(previoussnakeBodyPart *snakeBodyPart, currentsnakeBodyPart *snakeBodyPart, position Position) {
+	currentsnakeBodyPart.position = position
 	currentsnakeBodyPart.previousDirection = currentsnakeBodyPart.currentDirection
 	currentsnakeBodyPart.currentDirection = previoussnakeBodyPart.previousDirection
-	return nil
 }
 
-func moveHeadView(snakeHead *snakeBodyPart) error {
+func moveHeadView(snakeHead *snakeBodyPart) {
+	previousHeadPosition = snakeHead.position
 	snakeHead.previousDirection = snakeHead.currentDirection
 	snakeHead.currentDirection = headDirection
-
-	snakeHead.position = getPositionOfNextMove(snakeHead.currentDirection, snakeHead.position, true)
-	_, err := main.gui.SetView(
-		snakeHead.viewName,
-		snakeHead.position.x0,
-		snakeHead.position.y0,
-		snakeHead.position.x1,
-		snakeHead.position.y1,
-		0)
-	if err != nil {
-		return err
-	}
-	return nil
+	snakeHead.position = Step(snakeHead.position, snakeHead.currentDirection)
 }
 
 func getPositionOfNextMove(currentDirection Direction, currentPosition Position, isHead bool) Position {
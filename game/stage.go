@@ -0,0 +1,260 @@
+package game
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+	"time"
+)
+
+type GoalKind int
+type goalKinds struct {
+	Length  GoalKind
+	Golden  GoalKind
+	Survive GoalKind
+	Endless GoalKind
+}
+
+//What has to be done to clear a stage
+type Goal struct {
+	Kind GoalKind
+	//Length to reach or number of golden foods to eat
+	Target int
+	//Time to survive
+	Duration time.Duration
+}
+
+//A stage of the campaign: a board with walls, how the snake starts and the goal to reach.
+type Stage struct {
+	Number int
+	Name   string
+	Cols   int
+	Rows   int
+	Walls  []Cell
+	//The head starts at Start moving in StartDirection, or at a random position if HasStart is false
+	HasStart       bool
+	Start          Cell
+	StartDirection Direction
+	StartLength    int
+	//Starting tick interval of the speed curve
+	Speed time.Duration
+	Goal  Goal
+	//Cells of a Hamiltonian cycle through every floor cell but the portals, if one is known, for the autopilot to follow
+	Cycle []Cell
+	//Pairs of cells that lead to each other
+	Portals [][2]Cell
+}
+
+var (
+	GoalKinds = goalKinds{0, 1, 2, 3}
+	//The stage being played, nil outside the campaign
+	CurrentStage *Stage
+	//Called when the current stage is cleared. Returns the next stage, or nil if the campaign is complete.
+	StageCleared func(stage Stage) (*Stage, error)
+	walls        = make(map[Position]bool)
+	wallCells    = []Cell{}
+	nextStage    *Stage
+	stageClear   = false
+	goldenEaten  = 0
+	foodEaten    = 0
+	lastFoodTick = 0
+	survived     = time.Duration(0)
+	boardCols    = 0
+	boardRows    = 0
+)
+
+func (g Goal) String() string {
+	switch g.Kind {
+	case GoalKinds.Golden:
+		return fmt.Sprintf("Eat %d golden foods", g.Target)
+	case GoalKinds.Survive:
+		return fmt.Sprintf("Survive %v", g.Duration)
+	case GoalKinds.Endless:
+		return "Play on"
+	}
+	return fmt.Sprintf("Reach length %d", g.Target)
+}
+
+func goalReached() bool {
+	goal := CurrentStage.Goal
+	switch goal.Kind {
+	case GoalKinds.Golden:
+		return goldenEaten >= goal.Target
+	case GoalKinds.Survive:
+		return survived >= goal.Duration
+	case GoalKinds.Endless:
+		return false
+	}
+	return len(SnakeBodyParts) >= goal.Target
+}
+
+func goalProgress() string {
+	if BattleRoyale {
+		return arenaProgress()
+	}
+	if CurrentStage == nil {
+		return "-"
+	}
+	goal := CurrentStage.Goal
+	switch goal.Kind {
+	case GoalKinds.Golden:
+		return fmt.Sprintf("%d/%d golden", goldenEaten, goal.Target)
+	case GoalKinds.Survive:
+		return fmt.Sprintf("%v/%v", survived.Truncate(time.Second), goal.Duration)
+	case GoalKinds.Endless:
+		return CurrentStage.Name
+	}
+	return fmt.Sprintf("%d/%d length", len(SnakeBodyParts), goal.Target)
+}
+
+//Returns the number of columns and rows of the board the current stage is played on.
+func BoardSize() (int, int) {
+	return boardCols, boardRows
+}
+
+func IsWall(position Position) bool {
+	return walls[position]
+}
+
+//Positions the autopilot has to steer around: the snake, the walls, the closing border and the hazards
+//with the cells they can move into.
+func ObstacleSet() map[Position]bool {
+	obstacles := BoardObstacleSet()
+	for position := range GetsnakePositionSet(SnakeBodyParts) {
+		obstacles[position] = true
+	}
+	return obstacles
+}
+
+//The obstacles without the snake, which are all the autopilot has to steer around while the ghost effect is active.
+func BoardObstacleSet() map[Position]bool {
+	obstacles := make(map[Position]bool)
+	for position := range walls {
+		obstacles[position] = true
+	}
+	for position := range arenaObstacles() {
+		obstacles[position] = true
+	}
+	for position := range HazardSet() {
+		obstacles[position] = true
+	}
+	return obstacles
+}
+
+//Sets up the board for the current stage, or an empty board outside the campaign.
+func StartStage(positionMatrix [][]Position) error {
+	boardCols, boardRows = len(positionMatrix), 0
+	if boardCols > 0 {
+		boardRows = len(positionMatrix[0])
+	}
+	walls = make(map[Position]bool)
+	wallCells = []Cell{}
+	stageClear = false
+	goldenEaten = 0
+	foodEaten = 0
+	lastFoodTick = Tick
+	survived = 0
+	stageStart = 0
+	if CurrentStage == nil {
+		setPortals(nil, positionMatrix)
+		resetArena()
+		resetHazards(positionMatrix)
+		return view.UpdateGoal(goalProgress())
+	}
+
+	stage := CurrentStage
+	SnakeBodyParts = []*snakeBodyPart{snakeHead}
+	for _, cell := range stage.Walls {
+		if cell.Col < len(positionMatrix) && cell.Row < len(positionMatrix[cell.Col]) {
+			walls[positionMatrix[cell.Col][cell.Row]] = true
+			wallCells = append(wallCells, cell)
+		}
+	}
+	setPortals(stage.Portals, positionMatrix)
+	if stage.HasStart && stage.Start.Col < len(positionMatrix) && stage.Start.Row < len(positionMatrix[0]) {
+		snakeHead.position = positionMatrix[stage.Start.Col][stage.Start.Row]
+		headDirection = stage.StartDirection
+	} else {
+		if position, found := tryGetFreePosition(positionMatrix); found {
+			snakeHead.position = position
+		}
+	}
+	snakeHead.currentDirection = headDirection
+	snakeHead.previousDirection = headDirection
+	for len(SnakeBodyParts) < stage.StartLength {
+		tail := *SnakeBodyParts[len(SnakeBodyParts)-1]
+		if err := addBodyPartToEnd(tail); err != nil {
+			return err
+		}
+		//The starting body lies straight behind the start, which the stage check makes sure there is room for
+		SnakeBodyParts[len(SnakeBodyParts)-1].position = getPositionOfNextMove(tail.currentDirection, tail.position, false)
+	}
+	stageStart = stage.Speed
+	TickInterval = CurveTickInterval()
+	resetArena()
+	ResetFoods(positionMatrix)
+	resetHazards(positionMatrix)
+	return view.UpdateGoal(goalProgress())
+}
+
+//Counts the time survived and clears the stage once its goal is reached. Called once per tick.
+//Returns true if the stage was cleared.
+func UpdateStage(gui *gocui.Gui) (bool, error) {
+	if runOver {
+		return false, nil
+	}
+	survived += NextTickInterval(TickInterval)
+	if CurrentStage == nil {
+		return false, nil
+	}
+	if err := view.UpdateGoal(goalProgress()); err != nil {
+		return false, err
+	}
+	if !goalReached() {
+		return false, nil
+	}
+	return true, clearStage(gui)
+}
+
+//Ends the run as a success and turns the game over view into a stage clear screen.
+func clearStage(gui *gocui.Gui) error {
+	runOver = true
+	stageClear = true
+	nextStage = nil
+	if StageCleared != nil {
+		next, err := StageCleared(*CurrentStage)
+		if err != nil {
+			return err
+		}
+		nextStage = next
+	}
+	deathCause = ""
+	if err := summarize(); err != nil {
+		return err
+	}
+	if err := recordRun(); err != nil {
+		return err
+	}
+	if nextStage == nil {
+		if err := view.SetGameOverText(fmt.Sprint(actionLabel(ActionRestart), ": play again"), gameOverActions()); err != nil {
+			return err
+		}
+		//Stages outside the campaign, such as level files, have no number
+		if CurrentStage.Number == 0 {
+			return view.StageClear(gui, "Level clear!")
+		}
+		return view.StageClear(gui, "Campaign complete!")
+	}
+	if err := view.SetGameOverText(fmt.Sprint(actionLabel(ActionRestart), ": next stage"), gameOverActions()); err != nil {
+		return err
+	}
+	return view.StageClear(gui, fmt.Sprintf("Stage %d clear!", CurrentStage.Number))
+}
+
+//Moves on to the next stage if the current one was cleared.
+func advanceStage() {
+	if stageClear && nextStage != nil {
+		CurrentStage = nextStage
+	}
+	nextStage = nil
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"github.com/eiba/snake/game/view"
+	"time"
+)
+
+//A board cell, counted in cells rather than terminal columns and rows
+type Cell struct {
+	Col int
+	Row int
+}
+
+//A food on the board. Type is the name of its food type.
+type FoodCell struct {
+	Cell
+	Type string
+	//Ticks until the food disappears, or -1 if it does not expire
+	TicksLeft int
+}
+
+//A power-up on the board. Type is the name of its power-up type.
+type PowerUpCell struct {
+	Cell
+	Type string
+}
+
+//State is a snapshot of everything a renderer needs to draw a frame.
+type State struct {
+	Tick     int
+	Cols     int
+	Rows     int
+	Snake    []Cell
+	Walls    []Cell
+	Portals  []PortalCell
+	Foods    []FoodCell
+	PowerUps []PowerUpCell
+	Hazards  []Cell
+	Score    int
+	RunOver  bool
+	//Time until the next frame
+	TickInterval time.Duration
+	//Ticks left of the active power-up effects by name
+	Effects map[string]int
+	//Bodies of the enemy snakes, head first
+	Enemies [][]Cell
+	//Number of the bot steering each enemy snake, 0 for the ones the AI steers
+	EnemyBots []int
+	//Rings of the board closed in battle royale mode
+	ArenaRing int
+	//Whether the next ring flashes on this frame before it closes
+	ArenaWarning bool
+}
+
+func CurrentState(cols int, rows int) State {
+	snake := make([]Cell, len(SnakeBodyParts))
+	for i, bodyPart := range SnakeBodyParts {
+		snake[i] = CellOf(bodyPart.position)
+	}
+	foodCells := make([]FoodCell, len(foods))
+	for i, f := range foods {
+		foodCells[i] = FoodCell{CellOf(f.position), f.foodType.String(), f.ticksLeft()}
+	}
+	powerUpCells := make([]PowerUpCell, len(powerUps))
+	for i, p := range powerUps {
+		powerUpCells[i] = PowerUpCell{CellOf(p.position), p.powerUpType.String()}
+	}
+	hazardCells := make([]Cell, len(patrols))
+	for i, p := range patrols {
+		hazardCells[i] = CellOf(p.position)
+	}
+	enemyCells := make([][]Cell, len(enemies))
+	enemyBots := make([]int, len(enemies))
+	for i, e := range enemies {
+		enemyBots[i] = e.bot
+		enemyCells[i] = make([]Cell, len(e.body))
+		for j, position := range e.body {
+			enemyCells[i][j] = CellOf(position)
+		}
+	}
+	effects := make(map[string]int)
+	for powerUpType, ticksLeft := range activeEffects {
+		effects[powerUpType.String()] = ticksLeft
+	}
+	return State{
+		Tick:         Tick,
+		Cols:         cols,
+		Rows:         rows,
+		Snake:        snake,
+		Walls:        wallCells,
+		Portals:      portalCells,
+		Foods:        foodCells,
+		PowerUps:     powerUpCells,
+		Hazards:      hazardCells,
+		Score:        view.ScoreStat.Value,
+		RunOver:      runOver,
+		TickInterval: NextTickInterval(TickInterval),
+		Effects:      effects,
+		Enemies:      enemyCells,
+		EnemyBots:    enemyBots,
+		ArenaRing:    arenaRing,
+		ArenaWarning: arenaWarning() && Tick%2 == 0,
+	}
+}
+
+func CellOf(position Position) Cell {
+	return Cell{position.X0 / DeltaX, position.Y0 / DeltaY}
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game/view"
+	"time"
+)
+
+var (
+	//The time the ticks of the run took at their speed, leaving out pauses
+	timeStat      = view.RegisterStat("Game time")
+	foodStat      = view.RegisterStat("Food")
+	fillStat      = view.RegisterStat("Fill")
+	sinceFoodStat = view.RegisterStat("Since food")
+	bestStat      = view.RegisterStat("Best")
+	//The best score of the runs played since the game was started
+	sessionBest = 0
+)
+
+//Updates the stats that change with every tick, e.g. the time played and how much of the board the snake fills.
+func UpdateStats() error {
+	if view.ScoreStat.Value > sessionBest {
+		sessionBest = view.ScoreStat.Value
+	}
+	stats := []struct {
+		stat *view.Stat
+		text string
+	}{
+		{timeStat, fmt.Sprint(survived.Truncate(time.Second))},
+		{foodStat, fmt.Sprint(foodEaten)},
+		{fillStat, fmt.Sprintf("%.1f%%", boardFill()*100)},
+		{sinceFoodStat, fmt.Sprintf("%d moves", Tick-lastFoodTick)},
+		{bestStat, fmt.Sprint(sessionBest)},
+	}
+	for _, s := range stats {
+		if err := view.SetStatText(s.stat, s.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Returns the share of the cells the snake can take up that it does take up, which leaves out the walls, the portals
+//and the closed rings of the battle royale border.
+func boardFill() float64 {
+	free := 0
+	for col := 0; col < boardCols; col++ {
+		for row := 0; row < boardRows; row++ {
+			position := Position{col * DeltaX, row * DeltaY, (col + 1) * DeltaX, (row + 1) * DeltaY}
+			if _, portal := portals[position]; !walls[position] && !portal && !arenaClosed(position) {
+				free++
+			}
+		}
+	}
+	if free <= 0 {
+		return 0
+	}
+	return float64(len(SnakeBodyParts)) / float64(free)
+}
@@ -0,0 +1,117 @@
+package game
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game/view"
+	"github.com/eiba/snake/highscore"
+	"strings"
+	"time"
+)
+
+var (
+	//Saves the replay of the current run, returning where it was saved. Nil if runs are not recorded.
+	SaveReplay func() (string, error)
+	//Where the replay of the current run was saved, empty if it has not been saved
+	replaySaved = ""
+	//Set when the run ends, as the run is in the high score table afterwards
+	personalBest = false
+)
+
+//Shows the summary of the run that just ended on the game over modal.
+//Must be called before the run is recorded, to tell whether it is a new personal best.
+func summarize() error {
+	best, err := newPersonalBest()
+	if err != nil {
+		return err
+	}
+	personalBest = best
+	view.SetGameOverSummary(summaryLines()...)
+	return nil
+}
+
+func summaryLines() []string {
+	lines := []string{
+		fmt.Sprintf("Length:    %d", len(SnakeBodyParts)),
+		fmt.Sprintf("Score:     %d", view.ScoreStat.Value),
+		fmt.Sprintf("Game time: %v", survived.Truncate(time.Second)),
+		fmt.Sprintf("Ticks:     %d", Tick),
+		foodLine(),
+	}
+	if deathCause != "" {
+		lines = append(lines, fmt.Sprintf("Died:      %v", deathCause))
+	}
+	lines = append(lines, fmt.Sprintf("Seed:      %d", runSeed))
+	if personalBest {
+		lines = append(lines, "New personal best!")
+	}
+	if replaySaved != "" {
+		lines = append(lines, fmt.Sprintf("Replay saved to %v", replaySaved))
+	}
+	return lines
+}
+
+func foodLine() string {
+	if foodEaten == 0 {
+		return "Food:      0"
+	}
+	return fmt.Sprintf("Food:      %d, every %.1f ticks", foodEaten, float64(Tick)/float64(foodEaten))
+}
+
+//Reports whether the run beats every run in the high score table. Rewound runs are not recorded, so they never do.
+func newPersonalBest() (bool, error) {
+	if runRecorded || view.RewindStat.Value > 0 || view.ScoreStat.Value == 0 {
+		return false, nil
+	}
+	entries, err := highscore.Load()
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Score >= view.ScoreStat.Value {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func saveReplay(gui *gocui.Gui) error {
+	if SaveReplay == nil || replaySaved != "" {
+		return nil
+	}
+	path, err := SaveReplay()
+	if err != nil {
+		return err
+	}
+	replaySaved = path
+	view.SetGameOverSummary(summaryLines()...)
+	return nil
+}
+
+//Sets the keys of the game over modal from the key map: one plays the run again with the same seed, one saves its
+//replay and one shows the leaderboard. Starting a run with a new seed is left to the restart key.
+func initGameOverKeys(positionMatrix [][]Position) {
+	handlers := map[Action]func(gui *gocui.Gui) error{
+		ActionSameSeed: func(gui *gocui.Gui) error {
+			return reset(gui, positionMatrix, runSeed)
+		},
+		ActionSaveReplay: saveReplay,
+		ActionHighScores: openLeaderboard,
+	}
+	view.GameOverKeys = make(map[interface{}]func(gui *gocui.Gui) error)
+	for action, handler := range handlers {
+		for _, key := range actionKeys(action) {
+			view.GameOverKeys[key] = handler
+		}
+	}
+}
+
+//Returns the line telling which keys the game over modal handles, shown below the text of the restart key.
+func gameOverActions() string {
+	actions := []string{fmt.Sprint(actionLabel(ActionSameSeed), ": same seed")}
+	if SaveReplay != nil {
+		actions = append(actions, fmt.Sprint(actionLabel(ActionSaveReplay), ": save replay"))
+	}
+	actions = append(actions, fmt.Sprint(actionLabel(ActionHighScores), ": leaderboard"))
+	return strings.Join(actions, ", ")
+}
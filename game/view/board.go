@@ -0,0 +1,134 @@
+package view
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game"
+	"strings"
+)
+
+//Board draws every board cell into a single view. Each frame is composed with Set and written by Draw,
+//which only rewrites the cells that changed since the previous frame.
+type Board struct {
+	viewName       string
+	positionMatrix [][]game.Position
+	cols           int
+	rows           int
+	cells          []CellStyle
+	drawn          []CellStyle
+	resized        bool
+}
+
+var (
+	GameBoard  = &Board{}
+	emptyStyle = CellStyle{' ', gocui.ColorDefault, gocui.ColorDefault}
+	//Never set by a layer, so every cell differs from it and is redrawn
+	undrawnStyle = CellStyle{}
+)
+
+//Sets the view the board is drawn into and the board's positions. The whole board is redrawn if its size changed.
+func (b *Board) Resize(viewName string, positionMatrix [][]game.Position) {
+	b.viewName = viewName
+	b.positionMatrix = positionMatrix
+	cols, rows := len(positionMatrix), 0
+	if cols > 0 {
+		rows = len(positionMatrix[0])
+	}
+	if cols == b.cols && rows == b.rows {
+		return
+	}
+	b.cols, b.rows = cols, rows
+	b.cells = make([]CellStyle, cols*rows)
+	b.drawn = make([]CellStyle, cols*rows)
+	b.resized = true
+}
+
+func (b *Board) ViewName() string {
+	return b.viewName
+}
+
+func (b *Board) Size() (int, int) {
+	return b.cols, b.rows
+}
+
+func (b *Board) PositionMatrix() [][]game.Position {
+	return b.positionMatrix
+}
+
+//Starts a new frame with every cell empty.
+func (b *Board) Clear() {
+	for i := range b.cells {
+		b.cells[i] = emptyStyle
+	}
+}
+
+//Sets the cell at position in the current frame, ignoring positions outside the board.
+func (b *Board) Set(position game.Position, style CellStyle) {
+	b.SetCell(position.X0/game.DeltaX, position.Y0/game.DeltaY, style)
+}
+
+func (b *Board) SetCell(col int, row int, style CellStyle) {
+	if col < 0 || col >= b.cols || row < 0 || row >= b.rows {
+		return
+	}
+	b.cells[col*b.rows+row] = style
+}
+
+//Writes the cells that changed since the last call into the board's view.
+func (b *Board) Draw(gui *gocui.Gui) error {
+	v, err := gui.View(b.viewName)
+	if err != nil {
+		return err
+	}
+	if b.resized {
+		v.Clear()
+		b.Invalidate()
+		b.resized = false
+	}
+	for i, style := range b.cells {
+		if style == b.drawn[i] {
+			continue
+		}
+		col, row := i/b.rows, i%b.rows
+		if err := writeCell(v, col*game.DeltaX, row*game.DeltaY, style); err != nil {
+			return err
+		}
+		b.drawn[i] = style
+	}
+	return nil
+}
+
+//Forces the next Draw to rewrite every cell, e.g. after the view's content was cleared.
+func (b *Board) Invalidate() {
+	for i := range b.drawn {
+		b.drawn[i] = undrawnStyle
+	}
+}
+
+func writeCell(v *gocui.View, x int, y int, style CellStyle) error {
+	line := EscapeSequence(style) + string(style.Glyph) + strings.Repeat(" ", game.DeltaX-1) + "\033[0m"
+	for i := 0; i < game.DeltaY; i++ {
+		if err := v.SetWritePos(x, y+i); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(v, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Translates the style's colours to escape sequences, which both gocui views and terminals understand.
+func EscapeSequence(style CellStyle) string {
+	return colorEscape(style.Fg, 38, 30, 39) + colorEscape(style.Bg, 48, 40, 49)
+}
+
+func colorEscape(attribute gocui.Attribute, extendedCode int, normalCode int, defaultCode int) string {
+	if attribute == gocui.ColorDefault {
+		return fmt.Sprintf("\033[%dm", defaultCode)
+	}
+	if outputMode == gocui.Output256 {
+		return fmt.Sprintf("\033[%d;5;%dm", extendedCode, int(attribute)-1)
+	}
+	return fmt.Sprintf("\033[%dm", normalCode+int(attribute)-1)
+}
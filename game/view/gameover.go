@@ -2,44 +2,57 @@ package view
 
 import (
 	"github.com/awesome-gocui/gocui"
-	"github.com/eiba/snake/game"
 )
 
-const gameOverViewName = "gameOver"
+const (
+	gameOverViewName   = "gameOver"
+	stageClearViewName = "stageClear"
+)
 
-var gameOverView *gocui.View
+var (
+	//What the game over modal says below its title, e.g. which keys continue the game
+	gameOverText = []string{"Press space to restart"}
+	//The summary of the run shown above the game over text
+	gameOverSummary []string
+	//Keys handled while the game over or stage clear modal is open, on top of the game's keys
+	GameOverKeys map[interface{}]func(gui *gocui.Gui) error
+)
 
-func initGameOverView(gui *gocui.Gui, gameView Properties) error {
-	lenX, lenY, err := getLenXY(gui, gameView.Name)
-	if err != nil {
-		return err
-	}
+func GameOver(gui *gocui.Gui, title string) error {
+	return OpenModal(gui, &Modal{Name: gameOverViewName, Title: title, Lines: gameOverLines(), Keys: GameOverKeys})
+}
 
-	viewPositionX, viewPositionY := (lenX/2)-12, (lenY/2)-2
-	viewLenX := 25
-	viewLenY := 4
-
-	gameOverViewProperties := Properties{
-		Name: gameOverViewName,
-		Text: "Press space to restart",
-		Position: game.Position{
-			X0: viewPositionX,
-			Y0: viewPositionY,
-			X1: viewPositionX + viewLenX,
-			Y1: viewPositionY + viewLenY}}
-
-	gameOverView, err = createView(gui, gameOverViewProperties, false)
-	return err
+func SetGameOverText(text ...string) error {
+	gameOverText = text
+	SetModalLines(gameOverViewName, gameOverLines()...)
+	SetModalLines(stageClearViewName, gameOverLines()...)
+	return nil
 }
 
-func GameOver(gui *gocui.Gui, title string) error {
-	gameOverView.Visible = true
-	gameOverView.Title = title
-	if _, err := gui.SetCurrentView(gameOverViewName); err != nil {
-		return err
+//Sets the summary of the run shown by the game over and stage clear modals.
+func SetGameOverSummary(lines ...string) {
+	gameOverSummary = lines
+	SetModalLines(gameOverViewName, gameOverLines()...)
+	SetModalLines(stageClearViewName, gameOverLines()...)
+}
+
+func gameOverLines() []string {
+	if len(gameOverSummary) == 0 {
+		return gameOverText
 	}
-	if _, err := gui.SetViewOnTop(gameOverViewName); err != nil {
+	lines := append([]string{}, gameOverSummary...)
+	return append(append(lines, ""), gameOverText...)
+}
+
+//Shows that the stage is cleared, with the game over text telling how to go on.
+func StageClear(gui *gocui.Gui, title string) error {
+	return OpenModal(gui, &Modal{Name: stageClearViewName, Title: title, Lines: gameOverLines(), Keys: GameOverKeys})
+}
+
+//Hides the game over and stage clear modals, which gives the focus back to the game board.
+func HideGameOver(gui *gocui.Gui) error {
+	if err := CloseModal(gui, stageClearViewName); err != nil {
 		return err
 	}
-	return nil
+	return CloseModal(gui, gameOverViewName)
 }
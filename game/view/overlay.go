@@ -0,0 +1,160 @@
+package view
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+)
+
+//A modal shown over the game board, such as the pause or game over screen.
+//Open modals are kept on a stack: the top modal has the focus and gets the keys it has handlers for,
+//and the focus goes back to the game board once the last modal is closed.
+type Modal struct {
+	Name  string
+	Title string
+	Lines []string
+	//Keys handled while the modal is on top, as gocui keys or runes. Other keys reach the game.
+	Keys map[interface{}]func(gui *gocui.Gui) error
+	//Whether the game's keys are ignored while the modal is on top
+	CapturesInput bool
+	//Whether the snake stands still while the modal is open
+	PausesGame bool
+}
+
+var modals []*Modal
+
+//Opens modal on top of the stack. A modal that is already open is moved to the top.
+func OpenModal(gui *gocui.Gui, modal *Modal) error {
+	if gui == nil {
+		return nil
+	}
+	if err := CloseModal(gui, modal.Name); err != nil {
+		return err
+	}
+	modals = append(modals, modal)
+	for key, handler := range modal.Keys {
+		handler := handler
+		if err := gui.SetKeybinding(modal.Name, key, gocui.ModNone, func(gui *gocui.Gui, v *gocui.View) error {
+			return handler(gui)
+		}); err != nil {
+			return err
+		}
+	}
+	return LayoutModals(gui)
+}
+
+//Closes the modal called name, wherever it is on the stack, and gives the focus to the modal that is then on top.
+func CloseModal(gui *gocui.Gui, name string) error {
+	for i, modal := range modals {
+		if modal.Name != name {
+			continue
+		}
+		modals = append(modals[:i], modals[i+1:]...)
+		if gui == nil {
+			return nil
+		}
+		gui.DeleteKeybindings(name)
+		if err := gui.DeleteView(name); err != nil && !gocui.IsUnknownView(err) {
+			return err
+		}
+		return focusTopModal(gui)
+	}
+	return nil
+}
+
+func CloseModals(gui *gocui.Gui) error {
+	for len(modals) > 0 {
+		if err := CloseModal(gui, modals[len(modals)-1].Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ModalOpen(name string) bool {
+	for _, modal := range modals {
+		if modal.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+//Reports whether the modal on top keeps the game's keys to itself.
+func InputCaptured() bool {
+	return len(modals) > 0 && modals[len(modals)-1].CapturesInput
+}
+
+//Reports whether an open modal holds the snake still.
+func GamePaused() bool {
+	for _, modal := range modals {
+		if modal.PausesGame {
+			return true
+		}
+	}
+	return false
+}
+
+//Replaces the lines of the modal called name if it is open. A modal that is not open gets its lines when it is opened.
+func SetModalLines(name string, lines ...string) {
+	for _, modal := range modals {
+		if modal.Name == name {
+			modal.Lines = lines
+		}
+	}
+}
+
+//Centres the modals on the game board in stack order and gives the focus to the top one. Called on every layout,
+//so the modals follow the board when the terminal is resized.
+func LayoutModals(gui *gocui.Gui) error {
+	if gui == nil || len(modals) == 0 {
+		return nil
+	}
+	x0, y0, x1, y1, err := gui.ViewPosition(GameBoard.ViewName())
+	if err != nil {
+		x0, y0 = 0, 0
+		x1, y1 = gui.Size()
+	}
+	for _, modal := range modals {
+		width, height := modalSize(modal)
+		modalX, modalY := x0+(x1-x0-width)/2, y0+(y1-y0-height)/2
+		v, err := gui.SetView(modal.Name, modalX, modalY, modalX+width, modalY+height, 0)
+		if err != nil && !gocui.IsUnknownView(err) {
+			return err
+		}
+		v.Title = modal.Title
+		v.Clear()
+		fmt.Fprintln(v)
+		for _, line := range modal.Lines {
+			fmt.Fprintln(v, "", line)
+		}
+		if _, err := gui.SetViewOnTop(modal.Name); err != nil {
+			return err
+		}
+	}
+	return focusTopModal(gui)
+}
+
+//Returns the width and height of the modal's frame, fitting its title and lines below a blank line.
+func modalSize(modal *Modal) (int, int) {
+	width := len([]rune(modal.Title)) + 4
+	for _, line := range modal.Lines {
+		if len([]rune(line))+3 > width {
+			width = len([]rune(line)) + 3
+		}
+	}
+	return width, len(modal.Lines) + 2
+}
+
+func focusTopModal(gui *gocui.Gui) error {
+	name := GameBoard.ViewName()
+	if len(modals) > 0 {
+		name = modals[len(modals)-1].Name
+	}
+	if name == "" {
+		return nil
+	}
+	if _, err := gui.SetCurrentView(name); err != nil && !gocui.IsUnknownView(err) {
+		return err
+	}
+	return nil
+}
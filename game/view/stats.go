@@ -3,44 +3,123 @@ package view
 import (
 	"fmt"
 	"github.com/awesome-gocui/gocui"
+	"strings"
+	"time"
 )
 
 const statsViewName = "stats"
 
-var statsView *gocui.View
+var (
+	statsView *gocui.View
+	//Set when a stat changed since the stats view was last drawn. The view is drawn once per layout, not once per change.
+	statsChanged = false
+)
 
-type stat struct {
+//A line of the stats view. Counters such as the score keep their Value, other stats only the text they show.
+type Stat struct {
 	name  string
-	line  int
 	Value int
+	text  string
 }
 
 var (
-	LengthStat  = stat{"Length", 0, 1}
-	RestartStat = stat{"Restarts", 1, 0}
+	LengthStat  = Stat{name: "Length", Value: 1}
+	RestartStat = Stat{name: "Restarts"}
+	RewindStat  = Stat{name: "Rewinds"}
+	ScoreStat   = Stat{name: "Score"}
+	effectsStat = Stat{name: "Effects", text: "-"}
+	speedStat   = Stat{name: "Speed", text: "-"}
+	goalStat    = Stat{name: "Goal", text: "-"}
+	//The stats in the order they are listed, with the registered stats below the built-in ones
+	stats = []*Stat{&LengthStat, &RestartStat, &RewindStat, &ScoreStat, &effectsStat, &speedStat, &goalStat}
 )
 
+//Adds a stat called name below the other stats and returns it, so it can be updated with SetStatText.
+func RegisterStat(name string) *Stat {
+	stat := &Stat{name: name, text: "-"}
+	stats = append(stats, stat)
+	return stat
+}
+
+func (s Stat) String() string {
+	if s.text != "" {
+		return fmt.Sprint(s.name, ":", s.text)
+	}
+	return fmt.Sprint(s.name, ":", s.Value)
+}
+
+//Returns the last row of the stats view, which grows with the registered stats.
+func statsBottom() int {
+	return SidePanelOffset + len(stats) + 1
+}
+
+//Returns the rows the stats and step views take up below the keybindings view.
+func SidePanelHeight() int {
+	return len(stats) + 2 + stepViewHeight
+}
+
 func initStatsView(gui *gocui.Gui, gameView Properties) error {
 	maxX  := gameView.Position.X1
 
 	var err error
-	statsView, err = gui.SetView(statsViewName, maxX+1, 9, maxX+26, 12, 0)
+	statsView, err = gui.SetView(statsViewName, maxX+1, SidePanelOffset, maxX+SidePanelWidth+1, statsBottom(), 0)
 	if err != nil {
 		if !gocui.IsUnknownView(err) {
 			return err
 		}
 		statsView.Title = "Stats"
-
-		fmt.Fprintln(statsView, fmt.Sprint(LengthStat.name, ":", LengthStat.Value))
-		fmt.Fprintln(statsView, fmt.Sprint(RestartStat.name, ":", RestartStat.Value))
+		drawStats()
+	}
+	if statsChanged {
+		drawStats()
 	}
 	return nil
 }
 
-func UpdateStat(stat *stat, value int) error {
+func drawStats() {
+	if statsView == nil {
+		return
+	}
+	lines := make([]string, len(stats))
+	for i, stat := range stats {
+		lines[i] = stat.String()
+	}
+	statsView.Clear()
+	fmt.Fprint(statsView, strings.Join(lines, "\n"))
+	statsChanged = false
+}
+
+func UpdateStat(stat *Stat, value int) error {
 	stat.Value = value
-	if err := statsView.SetLine(stat.line, fmt.Sprint(stat.name, ":", stat.Value)); err != nil {
-		return err
+	statsChanged = true
+	return nil
+}
+
+//Sets what a stat that is not a counter shows, e.g. a time or a percentage.
+func SetStatText(stat *Stat, text string) error {
+	if text == "" {
+		text = "-"
 	}
+	stat.text = text
+	statsChanged = true
 	return nil
-}
\ No newline at end of file
+}
+
+//Shows the active power-up effects and their countdowns.
+func UpdateEffects(effects string) error {
+	return SetStatText(&effectsStat, effects)
+}
+
+//Shows the time between two moves, marked if the speed was changed by hand.
+func UpdateSpeed(tickInterval time.Duration, manual bool) error {
+	speed := fmt.Sprint(tickInterval.Round(time.Millisecond))
+	if manual {
+		speed += " (manual)"
+	}
+	return SetStatText(&speedStat, speed)
+}
+
+//Shows the progress towards the goal of the campaign stage.
+func UpdateGoal(progress string) error {
+	return SetStatText(&goalStat, progress)
+}
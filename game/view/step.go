@@ -0,0 +1,44 @@
+package view
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	stepViewName = "step"
+	//Rows of the step view, including its frame
+	stepViewHeight = 5
+)
+
+var stepView *gocui.View
+
+func InitStepView(gui *gocui.Gui, gameView Properties) error {
+	maxX := gameView.Position.X1
+
+	var err error
+	stepView, err = gui.SetView(stepViewName, maxX+1, statsBottom()+1, maxX+SidePanelWidth+1, statsBottom()+stepViewHeight, 0)
+	if err != nil {
+		if !gocui.IsUnknownView(err) {
+			return err
+		}
+		stepView.Title = "Step"
+		return UpdateStepView(0, "-", false)
+	}
+	return nil
+}
+
+func UpdateStepView(tick int, lastDecision string, stepMode bool) error {
+	if stepView == nil {
+		return nil
+	}
+	mode := "Running"
+	if stepMode {
+		mode = "Stepping"
+	}
+	stepView.Clear()
+	fmt.Fprintln(stepView, fmt.Sprint("Mode:", mode))
+	fmt.Fprintln(stepView, fmt.Sprint("Tick:", tick))
+	fmt.Fprintln(stepView, fmt.Sprint("Last:", lastDecision))
+	return nil
+}
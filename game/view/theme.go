@@ -0,0 +1,247 @@
+package view
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game"
+	"os"
+	"strings"
+)
+
+//A colour from the 256 colour palette, with a fallback for terminals with only 8 colours.
+//The first 8 entries of the palette are the 8 basic colours.
+type color struct {
+	index    int
+	fallback gocui.Attribute
+}
+
+type CellStyle struct {
+	Glyph rune
+	Fg    gocui.Attribute
+	Bg    gocui.Attribute
+}
+
+type cellTheme struct {
+	glyph rune
+	fg    color
+	bg    color
+}
+
+type Theme struct {
+	Name       string
+	Head       cellTheme
+	Body       cellTheme
+	Tail       cellTheme
+	Wall       cellTheme
+	Portal     cellTheme
+	Hazard     cellTheme
+	EnemyHead  cellTheme
+	EnemyBody  cellTheme
+	Warning    cellTheme
+	Food       map[string]cellTheme
+	PowerUp    map[string]cellTheme
+	Background color
+	//Palette indices the body fades through from head to tail, only used with 256 colours
+	Gradient []int
+}
+
+var (
+	defaultColor = color{-1, gocui.ColorDefault}
+	themes       = map[string]Theme{
+		"dark": {
+			Name:       "dark",
+			Head:       cellTheme{'●', color{16, gocui.ColorBlack}, color{118, gocui.ColorGreen}},
+			Body:       cellTheme{' ', defaultColor, color{34, gocui.ColorGreen}},
+			Tail:       cellTheme{'·', color{16, gocui.ColorBlack}, color{22, gocui.ColorGreen}},
+			Wall:       cellTheme{' ', defaultColor, color{240, gocui.ColorWhite}},
+			Portal:     cellTheme{'◎', color{201, gocui.ColorMagenta}, defaultColor},
+			Hazard:     cellTheme{'✚', color{16, gocui.ColorBlack}, color{202, gocui.ColorRed}},
+			EnemyHead:  cellTheme{'●', color{16, gocui.ColorBlack}, color{213, gocui.ColorMagenta}},
+			EnemyBody:  cellTheme{' ', defaultColor, color{133, gocui.ColorMagenta}},
+			Warning:    cellTheme{'!', color{196, gocui.ColorRed}, color{52, gocui.ColorRed}},
+			Background: color{234, gocui.ColorBlack},
+			Gradient:   []int{40, 34, 28, 22},
+			Food: map[string]cellTheme{
+				"regular": {'◆', color{196, gocui.ColorRed}, defaultColor},
+				"golden":  {'★', color{220, gocui.ColorYellow}, defaultColor},
+				"growth":  {'+', color{16, gocui.ColorBlack}, color{45, gocui.ColorCyan}},
+				"poison":  {'✖', color{129, gocui.ColorMagenta}, defaultColor},
+			},
+			PowerUp: map[string]cellTheme{
+				"slow-motion": {'S', color{16, gocui.ColorBlack}, color{75, gocui.ColorBlue}},
+				"ghost":       {'G', color{16, gocui.ColorBlack}, color{250, gocui.ColorWhite}},
+				"shrink":      {'X', color{16, gocui.ColorBlack}, color{208, gocui.ColorYellow}},
+			},
+		},
+		"light": {
+			Name:       "light",
+			Head:       cellTheme{'●', color{231, gocui.ColorWhite}, color{25, gocui.ColorBlue}},
+			Body:       cellTheme{' ', defaultColor, color{33, gocui.ColorBlue}},
+			Tail:       cellTheme{'·', color{231, gocui.ColorWhite}, color{117, gocui.ColorCyan}},
+			Wall:       cellTheme{' ', defaultColor, color{246, gocui.ColorBlack}},
+			Portal:     cellTheme{'◎', color{127, gocui.ColorMagenta}, defaultColor},
+			Hazard:     cellTheme{'✚', color{231, gocui.ColorWhite}, color{160, gocui.ColorRed}},
+			EnemyHead:  cellTheme{'●', color{231, gocui.ColorWhite}, color{90, gocui.ColorMagenta}},
+			EnemyBody:  cellTheme{' ', defaultColor, color{133, gocui.ColorMagenta}},
+			Warning:    cellTheme{'!', color{231, gocui.ColorWhite}, color{203, gocui.ColorRed}},
+			Background: color{255, gocui.ColorWhite},
+			Gradient:   []int{33, 39, 75, 117},
+			Food: map[string]cellTheme{
+				"regular": {'◆', color{160, gocui.ColorRed}, defaultColor},
+				"golden":  {'★', color{136, gocui.ColorYellow}, defaultColor},
+				"growth":  {'+', color{231, gocui.ColorWhite}, color{30, gocui.ColorCyan}},
+				"poison":  {'✖', color{90, gocui.ColorMagenta}, defaultColor},
+			},
+			PowerUp: map[string]cellTheme{
+				"slow-motion": {'S', color{231, gocui.ColorWhite}, color{26, gocui.ColorBlue}},
+				"ghost":       {'G', color{231, gocui.ColorWhite}, color{244, gocui.ColorBlack}},
+				"shrink":      {'X', color{231, gocui.ColorWhite}, color{166, gocui.ColorRed}},
+			},
+		},
+		"high-contrast": {
+			Name:       "high-contrast",
+			Head:       cellTheme{'@', color{0, gocui.ColorBlack}, color{11, gocui.ColorYellow}},
+			Body:       cellTheme{' ', defaultColor, color{15, gocui.ColorWhite}},
+			Tail:       cellTheme{'.', color{0, gocui.ColorBlack}, color{15, gocui.ColorWhite}},
+			Wall:       cellTheme{'#', color{0, gocui.ColorBlack}, color{15, gocui.ColorWhite}},
+			Portal:     cellTheme{'O', color{0, gocui.ColorBlack}, color{13, gocui.ColorMagenta}},
+			Hazard:     cellTheme{'%', color{15, gocui.ColorWhite}, color{9, gocui.ColorRed}},
+			EnemyHead:  cellTheme{'E', color{0, gocui.ColorBlack}, color{13, gocui.ColorMagenta}},
+			EnemyBody:  cellTheme{'e', color{0, gocui.ColorBlack}, color{13, gocui.ColorMagenta}},
+			Warning:    cellTheme{'!', color{0, gocui.ColorBlack}, color{9, gocui.ColorRed}},
+			Background: color{0, gocui.ColorBlack},
+			Food: map[string]cellTheme{
+				"regular": {'*', color{0, gocui.ColorBlack}, color{9, gocui.ColorRed}},
+				"golden":  {'$', color{0, gocui.ColorBlack}, color{11, gocui.ColorYellow}},
+				"growth":  {'+', color{0, gocui.ColorBlack}, color{14, gocui.ColorCyan}},
+				"poison":  {'!', color{15, gocui.ColorWhite}, color{13, gocui.ColorMagenta}},
+			},
+			PowerUp: map[string]cellTheme{
+				"slow-motion": {'S', color{15, gocui.ColorWhite}, color{12, gocui.ColorBlue}},
+				"ghost":       {'G', color{0, gocui.ColorBlack}, color{15, gocui.ColorWhite}},
+				"shrink":      {'X', color{0, gocui.ColorBlack}, color{10, gocui.ColorGreen}},
+			},
+		},
+		"monochrome": {
+			Name:       "monochrome",
+			Head:       cellTheme{'@', defaultColor, defaultColor},
+			Body:       cellTheme{'o', defaultColor, defaultColor},
+			Tail:       cellTheme{'.', defaultColor, defaultColor},
+			Wall:       cellTheme{'#', defaultColor, defaultColor},
+			Portal:     cellTheme{'O', defaultColor, defaultColor},
+			Hazard:     cellTheme{'%', defaultColor, defaultColor},
+			EnemyHead:  cellTheme{'E', defaultColor, defaultColor},
+			EnemyBody:  cellTheme{'e', defaultColor, defaultColor},
+			Warning:    cellTheme{'!', defaultColor, defaultColor},
+			Background: defaultColor,
+			Food: map[string]cellTheme{
+				"regular": {'*', defaultColor, defaultColor},
+				"golden":  {'$', defaultColor, defaultColor},
+				"growth":  {'+', defaultColor, defaultColor},
+				"poison":  {'!', defaultColor, defaultColor},
+			},
+			PowerUp: map[string]cellTheme{
+				"slow-motion": {'S', defaultColor, defaultColor},
+				"ghost":       {'G', defaultColor, defaultColor},
+				"shrink":      {'X', defaultColor, defaultColor},
+			},
+		},
+	}
+	CurrentTheme = themes["dark"]
+	outputMode   = gocui.OutputNormal
+)
+
+//Detects the number of colours the terminal supports. Truecolor terminals also support 256 colours,
+//which is the most gocui can output.
+func DetectOutputMode() gocui.OutputMode {
+	colorTerm := os.Getenv("COLORTERM")
+	if colorTerm == "truecolor" || colorTerm == "24bit" || strings.Contains(os.Getenv("TERM"), "256color") {
+		outputMode = gocui.Output256
+	} else {
+		outputMode = gocui.OutputNormal
+	}
+	return outputMode
+}
+
+//Sets the number of colours explicitly, e.g. when exporting replays, which are not tied to a terminal.
+func UseOutputMode(mode gocui.OutputMode) {
+	outputMode = mode
+}
+
+func UseTheme(name string) error {
+	theme, exist := themes[name]
+	if !exist {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+	CurrentTheme = theme
+	return nil
+}
+
+func (c color) attribute() gocui.Attribute {
+	if c.index < 0 {
+		return gocui.ColorDefault
+	}
+	if outputMode == gocui.Output256 {
+		return gocui.Attribute(c.index + 1)
+	}
+	return c.fallback
+}
+
+func (c cellTheme) style() CellStyle {
+	return CellStyle{c.glyph, c.fg.attribute(), c.bg.attribute()}
+}
+
+func BackgroundColor() gocui.Attribute {
+	return CurrentTheme.Background.attribute()
+}
+
+//Returns the style of the food type with the given name.
+func FoodStyle(foodType string) CellStyle {
+	return CurrentTheme.Food[foodType].style()
+}
+
+func WallStyle() CellStyle {
+	return CurrentTheme.Wall.style()
+}
+
+func PortalStyle() CellStyle {
+	return CurrentTheme.Portal.style()
+}
+
+func HazardStyle() CellStyle {
+	return CurrentTheme.Hazard.style()
+}
+
+//Returns the style of the ring that flashes before the battle royale border closes in on it.
+func WarningStyle() CellStyle {
+	return CurrentTheme.Warning.style()
+}
+
+//Returns the style of the segment at index of an enemy snake.
+func EnemyStyle(index int) CellStyle {
+	if index == 0 {
+		return CurrentTheme.EnemyHead.style()
+	}
+	return CurrentTheme.EnemyBody.style()
+}
+
+//Returns the style of the power-up type with the given name.
+func PowerUpStyle(powerUpType string) CellStyle {
+	return CurrentTheme.PowerUp[powerUpType].style()
+}
+
+//Returns the style of the body part at index in a snake of the given length.
+func SnakeStyle(index int, length int) CellStyle {
+	if index == 0 {
+		return CurrentTheme.Head.style()
+	}
+	if index == length-1 {
+		return CurrentTheme.Tail.style()
+	}
+	style := CurrentTheme.Body.style()
+	if outputMode == gocui.Output256 && len(CurrentTheme.Gradient) > 0 {
+		gradientIndex := (index - 1) * len(CurrentTheme.Gradient) / length
+		style.Bg = gocui.Attribute(CurrentTheme.Gradient[gradientIndex] + 1)
+	}
+	return style
+}
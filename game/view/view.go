@@ -1,14 +1,18 @@
 package view
 
 import (
-	"fmt"
-	"github.com/awesome-gocui/gocui"
 	"github.com/eiba/snake/game"
 	"math/rand"
 	"time"
 )
 var r = rand.New(rand.NewSource(time.Now().UnixNano()))
 
+var (
+	SidePanelWidth = 25
+	//The first free row of the side panel below the keybindings view
+	SidePanelOffset = 0
+)
+
 type Properties struct {
 	Name     string
 	Title    string
@@ -16,77 +20,24 @@ type Properties struct {
 	Position game.Position
 }
 
-func getLenXY(gui *gocui.Gui, viewName string) (int, int, error) {
-	x0, y0, x1, y1, err := gui.ViewPosition(viewName)
-	if err != nil {
-		return 0, 0, err
-	}
-	return x1 - x0, y1 - y0, nil
-}
-
-func createView(gui *gocui.Gui, viewProperties Properties, visible bool) (*gocui.View, error) {
-	view, err := gui.SetView(viewProperties.Name, viewProperties.Position.X0, viewProperties.Position.Y0, viewProperties.Position.X1, viewProperties.Position.Y1, 0)
-	if err != nil {
-		if !gocui.IsUnknownView(err) {
-			return nil, err
-		}
-
-		view.Title = viewProperties.Title
-		view.Visible = visible
-		fmt.Fprintln(view, "\n", viewProperties.Text)
-	}
-	return view, nil
-}
-
-func setViewPosition(gui *gocui.Gui, name string, position game.Position) error {
-	_, err := gui.SetView(name, position.X0, position.Y0, position.X1, position.Y1, 0)
-	if err != nil && !gocui.IsUnknownView(err) {
-		return err
-	}
-	return nil
+//Seeds the random positions, so a run started with the same seed places its food the same way.
+func Seed(seed int64) {
+	r.Seed(seed)
 }
 
-func setCurrentView(gui *gocui.Gui,name string) error {
-	if _, err := gui.SetCurrentView(name); err != nil {
-		return err
-	}
-	return nil
-}
-
-func SetViewAtRandomPosition(gui *gocui.Gui, name string, positionMatrix [][]game.Position, setCurrent bool) (game.Position, error) {
-	randomPosition := getRandomPosition(positionMatrix)
-	if err := setViewPosition(gui, name, randomPosition); err != nil {
-		return game.Position{}, err
-	}
-
-	if setCurrent {
-		if err := setCurrentView(gui, name); err != nil {
-			return game.Position{}, err
-		}
-	}
-	return randomPosition, nil
-}
-
-func getRandomPosition(positionMatrix [][]game.Position) game.Position {
+func GetRandomPosition(positionMatrix [][]game.Position) game.Position {
 	return positionMatrix[r.Intn(len(positionMatrix))][r.Intn(len(positionMatrix[0]))]
 }
 
-func TrySetViewAtRandomEmptyPosition(gui *gocui.Gui, name string, positionMatrix [][]game.Position) (game.Position, bool, error) {
-	randomPosition, foundEmptyPosition := tryGetRandomEmptyPosition(positionMatrix)
-	if !foundEmptyPosition {
-		return randomPosition, foundEmptyPosition, nil
-	}
-	if err := setViewPosition(gui, name, randomPosition); err != nil {
-		return game.Position{}, foundEmptyPosition, err
-	}
-	return randomPosition, foundEmptyPosition, nil
+func TryGetRandomEmptyPosition(positionMatrix [][]game.Position) (game.Position, bool) {
+	return TryGetRandomFreePosition(positionMatrix, game.GetsnakePositionSet(game.SnakeBodyParts))
 }
 
-func tryGetRandomEmptyPosition(positionMatrix [][]game.Position) (game.Position, bool) {
+//Finds a random position that is not in occupied, e.g. one that is neither taken by the snake nor by food.
+func TryGetRandomFreePosition(positionMatrix [][]game.Position, occupied map[game.Position]bool) (game.Position, bool) {
 	randomCol := r.Intn(len(positionMatrix))
 	randomRow := r.Intn(len(positionMatrix[0]))
-	snakePositionSet := game.GetsnakePositionSet(game.SnakeBodyParts)
-	emptyPosition, foundEmptyPosition := tryGetEmptyPosition(snakePositionSet, positionMatrix, randomCol, randomRow)
+	emptyPosition, foundEmptyPosition := tryGetEmptyPosition(occupied, positionMatrix, randomCol, randomRow)
 	return emptyPosition, foundEmptyPosition
 }
 
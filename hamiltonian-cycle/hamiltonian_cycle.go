@@ -13,14 +13,29 @@ type node struct {
 var (
 	hCycle        []node
 	cycleIndexMap map[game.Position]int
+	//The stage whose cycle is in use, nil if the cycle was generated for an empty board
+	cycleStage *game.Stage
 )
 
 func initHamiltonianCycle(gameViewPosition game.Position, PositionMatrix [][]game.Position, autoPilot bool) error {
 	gameViewCols := gameViewPosition.X1 / game.DeltaX
 	gameViewRows := gameViewPosition.X1 / game.DeltaY
-	if len(hCycle)-1 == gameViewCols*gameViewRows || !autoPilot {
+	if stage := game.CurrentStage; stage != nil {
+		if cycleStage != stage && autoPilot {
+			//A stage whose floor has no cycle gets none, as the cycle of the empty board would lead into its walls
+			hCycle = nil
+			if len(stage.Cycle) > 0 {
+				hCycle = cycleFromCells(stage.Cycle, PositionMatrix)
+			}
+			cycleIndexMap = generateHamiltonianCycleIndexMap(hCycle)
+			cycleStage = stage
+		}
+		return nil
+	}
+	if (len(hCycle)-1 == gameViewCols*gameViewRows && cycleStage == nil) || !autoPilot {
 		return nil
 	}
+	cycleStage = nil
 
 	if err := view.Loading(true); err != nil {
 		return err
@@ -52,6 +67,26 @@ func generateHamiltonianCycle(PositionMatrix [][]game.Position) []node {
 	return tour
 }
 
+//Turns a cycle of cells, such as the one of a generated maze, into the nodes of a tour.
+func cycleFromCells(cells []game.Cell, PositionMatrix [][]game.Position) []node {
+	tour := make([]node, len(cells)+1)
+	for i, cell := range cells {
+		next := cells[(i+1)%len(cells)]
+		direction := game.Directions.Right
+		switch {
+		case next.Row < cell.Row:
+			direction = game.Directions.Up
+		case next.Row > cell.Row:
+			direction = game.Directions.Down
+		case next.Col < cell.Col:
+			direction = game.Directions.Left
+		}
+		tour[i] = node{direction, PositionMatrix[cell.Col][cell.Row]}
+	}
+	tour[len(cells)] = tour[0]
+	return tour
+}
+
 func hamiltonianCycle(usedPositions map[game.Position]bool, tour []node, moveNumber int, totalMoves int, vertexGraph [][][]game.Direction, PositionMatrix [][]game.Position) []node {
 	previousNode := tour[moveNumber-1]
 	nextCol, nextRow := getNextPosition(previousNode)
@@ -173,3 +208,17 @@ func generateHamiltonianCycleIndexMap(hamiltonianCycle []node) map[game.Position
 	}
 	return indexMap
 }
+
+//CycleNode returns the direction leaving position in the Hamiltonian cycle and its index in the cycle.
+func CycleNode(position game.Position) (game.Direction, int, bool) {
+	index, exist := cycleIndexMap[position]
+	if !exist {
+		return 0, 0, false
+	}
+	return hCycle[index].direction, index, true
+}
+
+//CycleLength returns the number of positions in the current Hamiltonian cycle.
+func CycleLength() int {
+	return len(cycleIndexMap)
+}
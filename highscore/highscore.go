@@ -0,0 +1,90 @@
+package highscore
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/eiba/snake/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	maxEntries = 10
+	fileName   = "highscores.json"
+)
+
+type Entry struct {
+	Score      int
+	Length     int
+	Difficulty string
+	//Set if the speed was changed by hand during the run, so it did not follow the difficulty's speed curve
+	SpeedOverride bool
+	Date          time.Time
+}
+
+//Formats the entry as a line of the leaderboard.
+func (e Entry) String() string {
+	line := fmt.Sprintf("%6d  length %-4d %-7v %v", e.Score, e.Length, e.Difficulty, e.Date.Format("2006-01-02"))
+	//Runs where the speed was changed by hand are not comparable to the others
+	if e.SpeedOverride {
+		line += " *"
+	}
+	return line
+}
+
+//Loads the high score table, returning an empty table if none has been saved yet.
+func Load() ([]Entry, error) {
+	filePath, err := config.FilePath(fileName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func save(entries []Entry) error {
+	filePath, err := config.FilePath(fileName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+//Adds entry to the high score table, keeping only the best entries.
+func Add(entry Entry) error {
+	entries, err := Load()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].Length > entries[j].Length
+	})
+	if len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+	return save(entries)
+}
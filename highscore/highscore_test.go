@@ -0,0 +1,122 @@
+package highscore
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+//Points the user config directory at a new directory, so the tests neither read nor write the real high scores.
+//The directory is removed and the environment restored by the returned function.
+func tempConfigDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "snake-highscore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, exist := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	return func() {
+		if exist {
+			os.Setenv("XDG_CONFIG_HOME", previous)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	date := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		added []Entry
+		want  []Entry
+	}{
+		{"keeps difficulty and speed override",
+			[]Entry{{Score: 10, Length: 5, Difficulty: "hard", SpeedOverride: true, Date: date}},
+			[]Entry{{Score: 10, Length: 5, Difficulty: "hard", SpeedOverride: true, Date: date}}},
+		{"ranked by length",
+			[]Entry{{Length: 3, Date: date}, {Length: 8, Date: date}},
+			[]Entry{{Length: 8, Date: date}, {Length: 3, Date: date}}},
+		{"ranked by score",
+			[]Entry{{Score: 5, Length: 9, Date: date}, {Score: 20, Length: 2, Date: date}},
+			[]Entry{{Score: 20, Length: 2, Date: date}, {Score: 5, Length: 9, Date: date}}},
+		{"ties ranked by length",
+			[]Entry{{Score: 5, Length: 3, Date: date}, {Score: 5, Length: 8, Date: date}},
+			[]Entry{{Score: 5, Length: 8, Date: date}, {Score: 5, Length: 3, Date: date}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer tempConfigDir(t)()
+			for _, entry := range test.added {
+				if err := Add(entry); err != nil {
+					t.Fatal(err)
+				}
+			}
+			entries, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(entries, test.want) {
+				t.Errorf("got %+v, want %+v", entries, test.want)
+			}
+		})
+	}
+}
+
+func TestAddKeepsBestEntries(t *testing.T) {
+	defer tempConfigDir(t)()
+	for length := 1; length <= maxEntries+5; length++ {
+		if err := Add(Entry{Length: length}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxEntries {
+		t.Fatalf("got %d entries, want %d", len(entries), maxEntries)
+	}
+	if entries[0].Length != maxEntries+5 || entries[maxEntries-1].Length != 6 {
+		t.Errorf("kept lengths %d to %d, want %d to 6", entries[0].Length, entries[maxEntries-1].Length, maxEntries+5)
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	defer tempConfigDir(t)()
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("got %+v, want no entries", entries)
+	}
+}
+
+func TestEntryString(t *testing.T) {
+	date := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		entry Entry
+		want  []string
+		//Set if the line must not end with the speed override marker
+		plain bool
+	}{
+		{Entry{Score: 42, Length: 7, Difficulty: "hard", Date: date}, []string{"42", "length 7", "hard", "2026-10-19"}, true},
+		{Entry{Score: 3, Length: 2, Difficulty: "easy", SpeedOverride: true, Date: date}, []string{"3", "easy", "*"}, false},
+	}
+	for _, test := range tests {
+		line := test.entry.String()
+		for _, want := range test.want {
+			if !strings.Contains(line, want) {
+				t.Errorf("%q does not contain %q", line, want)
+			}
+		}
+		if test.plain && strings.HasSuffix(line, "*") {
+			t.Errorf("%q is marked as speed override", line)
+		}
+	}
+}
@@ -1,42 +1,292 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"github.com/awesome-gocui/gocui"
 	"github.com/eiba/snake/autopilot"
+	"github.com/eiba/snake/campaign"
+	"github.com/eiba/snake/config"
+	"github.com/eiba/snake/editor"
 	"github.com/eiba/snake/game"
 	"github.com/eiba/snake/game/view"
 	"github.com/eiba/snake/hamiltonian-cycle"
+	"github.com/eiba/snake/maze"
+	"github.com/eiba/snake/menu"
+	"github.com/eiba/snake/render"
+	"github.com/eiba/snake/replay"
+	"github.com/eiba/snake/spectator"
+	"io/ioutil"
 	"log"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"time"
 )
 
+//Frames kept for the replay of the current run, the last several minutes at any speed
+const runReplayFrames = 10000
+
 var (
 	gui              *gocui.Gui
 	r                = rand.New(rand.NewSource(time.Now().UnixNano()))
 	Running          = true
 	GameFinished     = false
 	AutoPilotEnabled = false
-	tickInterval     = 50 * time.Millisecond
 	gameView         = view.Properties{"game", "snake", "", game.Position{}}
 	positionMatrix   [][]game.Position
+	renderer         render.Renderer
+	//The size of the gocui board in cells, or zero to fill the terminal
+	boardSize = game.Cell{}
 )
 
 func main() {
+	cfg, args, err := config.Load(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if len(args) == 2 && args[0] == "config" && args[1] == "print" {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	if err := applyConfig(cfg); err != nil {
+		log.Fatalln(err)
+	}
+	if len(args) > 0 && args[0] == "export" {
+		if err := exportReplay(args[1:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "edit" {
+		if len(args) != 2 {
+			log.Fatalln("edit: expected one level file")
+		}
+		if err := editor.Run(args[1], cfg.BoardCols, cfg.BoardRows); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	if showMenu(cfg) {
+		choice, chosen, err := menu.Run(cfg)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		switch choice {
+		case menu.Choices.Quit:
+			return
+		case menu.Choices.Versus:
+			chosen.BattleRoyale = true
+			//There is no edge to wrap around once the border starts closing
+			chosen.Wrap = false
+		case menu.Choices.Campaign:
+			chosen.Campaign = true
+		}
+		cfg = chosen
+		if err := applyConfig(cfg); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if cfg.Campaign {
+		if err := startCampaign(&cfg); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if cfg.LevelPath != "" {
+		if err := startLevel(&cfg); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if cfg.Maze != "" {
+		if err := startMaze(cfg); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if cfg.Renderer != render.TUIName {
+		if err := runHeadless(cfg); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	gui = initGUI()
 	defer gui.Close()
+	renderer, err = withSpectator(cfg, withRecorder(cfg, render.NewTUI(gui)), func(snake int, direction game.Direction) {
+		gui.Update(func(gui *gocui.Gui) error {
+			game.Steer(snake, direction)
+			return nil
+		})
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer renderer.Close()
 
 	if err := game.initKeybindings(); err != nil {
 		log.Panicln(err)
 	}
+	if err := autopilot.InitOverlayKey(gui); err != nil {
+		log.Panicln(err)
+	}
+	game.BoardLayers = append(game.BoardLayers, autopilot.DrawOverlay)
+	if err := game.InitStepKeys(gui, tick); err != nil {
+		log.Panicln(err)
+	}
+	if err := game.InitHelpKey(gui); err != nil {
+		log.Panicln(err)
+	}
+	if err := game.InitPauseKey(gui, &GameFinished); err != nil {
+		log.Panicln(err)
+	}
+	if err := game.InitRewindKeys(gui, &GameFinished, &Running); err != nil {
+		log.Panicln(err)
+	}
 
 	if err := gui.MainLoop(); err != nil && !gocui.IsQuit(err) {
 		log.Panicln(err)
 	}
 }
 
+//The menu opens before games in the terminal UI, unless a game mode was already picked with the flags.
+func showMenu(cfg config.Config) bool {
+	return cfg.Menu && cfg.Renderer == render.TUIName && !cfg.Campaign && cfg.LevelPath == "" && cfg.Maze == "" && !cfg.BattleRoyale
+}
+
+func applyConfig(cfg config.Config) error {
+	AutoPilotEnabled = cfg.AutoPilot
+	if err := autopilot.UseStrategy(cfg.Strategy); err != nil {
+		return err
+	}
+	game.Wrap = cfg.Wrap
+	boardSize = game.Cell{}
+	if !cfg.FitTerminal {
+		boardSize = game.Cell{Col: cfg.BoardCols, Row: cfg.BoardRows}
+	}
+	game.DeltaX = cfg.DeltaX
+	game.DeltaY = cfg.DeltaY
+	game.InputQueueDepth = cfg.InputQueueDepth
+	game.CasualMode = cfg.CasualMode
+	game.RewindSeconds = cfg.RewindSeconds
+	game.FoodSpawnRates[game.FoodTypes.Golden] = cfg.GoldenFoodRate
+	game.FoodSpawnRates[game.FoodTypes.Growth] = cfg.GrowthFoodRate
+	game.FoodSpawnRates[game.FoodTypes.Poison] = cfg.PoisonFoodRate
+	game.GoldenFoodTicks = cfg.GoldenFoodTicks
+	game.PoisonFoodTicks = cfg.PoisonFoodTicks
+	game.PowerUpSpawnRate = cfg.PowerUpRate
+	game.PowerUpDuration = cfg.PowerUpTicks
+	game.PowerUpLifetime = cfg.PowerUpLifetime
+	game.SlowMotionFactor = cfg.SlowMotion
+	game.PatrolCount = cfg.Hazards
+	game.EnemyCount = cfg.Enemies
+	game.BotCount = cfg.Bots
+	game.BattleRoyale = cfg.BattleRoyale
+	game.ShrinkInterval = cfg.ShrinkInterval
+	game.ShrinkWarningTicks = cfg.ShrinkWarning
+	if cfg.BattleRoyale && cfg.Enemies == 0 && cfg.Bots == 0 {
+		game.EnemyCount = game.BattleRoyaleRivals
+	}
+	if err := applyDifficulty(cfg); err != nil {
+		return err
+	}
+	view.SidePanelWidth = cfg.SidePanelWidth
+	if err := view.UseTheme(cfg.Theme); err != nil {
+		return err
+	}
+	return game.UseKeyMap(cfg.Keybindings.Preset, cfg.Keybindings.Bindings)
+}
+
+//Selects the difficulty's speed curve, with the start, step and floor overridden where they are configured.
+func applyDifficulty(cfg config.Config) error {
+	if err := game.UseDifficulty(cfg.Difficulty); err != nil {
+		return err
+	}
+	if cfg.TickInterval.Duration != 0 {
+		game.CurrentDifficulty.Start = cfg.TickInterval.Duration
+	}
+	if cfg.SpeedStep.Duration != 0 {
+		game.CurrentDifficulty.Step = cfg.SpeedStep.Duration
+	}
+	if cfg.SpeedFloor.Duration != 0 {
+		game.CurrentDifficulty.Floor = cfg.SpeedFloor.Duration
+	}
+	game.TickInterval = game.CurveTickInterval()
+	return nil
+}
+
+//Selects the campaign stage to play. The board takes the size of the stage.
+func startCampaign(cfg *config.Config) error {
+	progress, err := campaign.LoadProgress()
+	if err != nil {
+		return err
+	}
+	number := cfg.Stage
+	if number == 0 {
+		number = progress.Unlocked
+		if number > campaign.StageCount() {
+			number = campaign.StageCount()
+		}
+	}
+	if number > progress.Unlocked {
+		return fmt.Errorf("stage %d is locked, clear stage %d first", number, progress.Unlocked)
+	}
+	stage, err := campaign.Stage(number)
+	if err != nil {
+		return err
+	}
+	game.CurrentStage = stage
+	game.StageCleared = campaign.NextStage
+	cfg.BoardCols, cfg.BoardRows = stage.Cols, stage.Rows
+	return nil
+}
+
+//Loads a level file to play on its own. The board takes the size of the level.
+func startLevel(cfg *config.Config) error {
+	data, err := ioutil.ReadFile(cfg.LevelPath)
+	if err != nil {
+		return err
+	}
+	stage, err := campaign.Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("%v: %v", cfg.LevelPath, err)
+	}
+	game.CurrentStage = &stage
+	cfg.BoardCols, cfg.BoardRows = stage.Cols, stage.Rows
+	return nil
+}
+
+//Generates the board to play from the maze settings, with a random seed unless one is configured.
+func startMaze(cfg config.Config) error {
+	seed := int64(cfg.MazeSeed)
+	if seed == 0 {
+		seed = r.Int63n(1000000) + 1
+	}
+	density := cfg.MazeDensity
+	if density < 0 {
+		density = game.CurrentDifficulty.MazeDensity
+	}
+	stage, err := maze.Generate(maze.Options{
+		Style:         cfg.Maze,
+		Seed:          seed,
+		Cols:          cfg.BoardCols,
+		Rows:          cfg.BoardRows,
+		Density:       density,
+		CorridorWidth: cfg.CorridorWidth,
+		Speed:         game.CurrentDifficulty.Start,
+	})
+	if err != nil {
+		return err
+	}
+	game.CurrentStage = stage
+	return nil
+}
+
 func initGUI() *gocui.Gui {
-	gui, err := gocui.NewGui(gocui.OutputNormal, true)
+	gui, err := gocui.NewGui(view.DetectOutputMode(), true)
 	if err != nil {
 		log.Panicln(err)
 	}
@@ -52,7 +302,11 @@ func initGameView(maxX int, maxY int) (game.position, error) {
 		if !gocui.IsUnknownView(err) {
 			return gameViewPosition, err
 		}
+		if err := checkBoardFits(gameViewPosition, maxX, maxY); err != nil {
+			return gameViewPosition, err
+		}
 		v.Title = "snake"
+		v.BgColor = view.BackgroundColor()
 		if _, err := gui.SetViewOnBottom(gameView.name); err != nil {
 			return gameViewPosition, err
 		}
@@ -62,8 +316,33 @@ func initGameView(maxX int, maxY int) (game.position, error) {
 	return gameViewPosition, nil
 }
 
+//Stages have a fixed size, which is cut off by a terminal too small to show it next to the side panel.
+func checkBoardFits(gameViewPosition game.position, maxX int, maxY int) error {
+	if game.CurrentStage == nil {
+		return nil
+	}
+	width, height := gameViewPosition.x1+view.SidePanelWidth, gameViewPosition.y1+1
+	if width > maxX || height > maxY {
+		return fmt.Errorf("the terminal is %dx%d, but %q needs at least %dx%d", maxX, maxY, game.CurrentStage.Name, width, height)
+	}
+	return nil
+}
+
 func calculateGameViewPosition(maxX int, maxY int) game.position {
-	defaultPosition := game.position{0, 0, maxX - 25, maxY - 1}
+	defaultPosition := game.position{0, 0, maxX - view.SidePanelWidth, maxY - 1}
+	//Campaign stages and generated boards have a fixed size
+	if game.CurrentStage != nil {
+		defaultPosition.x1 = game.CurrentStage.Cols * game.DeltaX
+		defaultPosition.y1 = game.CurrentStage.Rows * game.DeltaY
+		return defaultPosition
+	}
+	//A configured board size is used as far as it fits the terminal
+	if boardSize.Col > 0 && boardSize.Col*game.DeltaX < defaultPosition.x1 {
+		defaultPosition.x1 = boardSize.Col * game.DeltaX
+	}
+	if boardSize.Row > 0 && boardSize.Row*game.DeltaY < defaultPosition.y1 {
+		defaultPosition.y1 = boardSize.Row * game.DeltaY
+	}
 
 	if defaultPosition.x1%2 != 0 {
 		defaultPosition.x1--
@@ -82,13 +361,14 @@ func calculateGameViewPosition(maxX int, maxY int) game.position {
 }
 
 func initGame() error {
-	var err error
-	game.snakeHead.position, err = view.setViewAtRandomPosition(game.snakeHead.viewName, positionMatrix, true)
-	if err != nil {
+	game.Reseed(0)
+	game.snakeHead.position = view.GetRandomPosition(positionMatrix)
+	game.ResetFoods(positionMatrix)
+	if err := game.StartStage(positionMatrix); err != nil {
 		return err
 	}
-	game.foodView.position, err = view.setViewAtRandomPosition(game.foodView.name, positionMatrix, false)
-	if err != nil {
+	view.GameBoard.Resize(gameView.Name, positionMatrix)
+	if _, err := gui.SetCurrentView(gameView.Name); err != nil {
 		return err
 	}
 	go updateMovement()
@@ -112,15 +392,12 @@ func manageGame(gui *gocui.Gui) error {
 		log.Panicln(err)
 	}
 
-	if err := view.initLoadingView(); err != nil {
+	if err := view.InitStepView(gui, gameView); err != nil {
 		log.Panicln(err)
 	}
 
-	if err := view.initPauseView(); err != nil {
-		log.Panicln(err)
-	}
-
-	if err := view.initGameOverView(); err != nil {
+	//Modals are placed last so they stay on top of the board and the side panel
+	if err := view.LayoutModals(gui); err != nil {
 		log.Panicln(err)
 	}
 	return nil
@@ -128,28 +405,200 @@ func manageGame(gui *gocui.Gui) error {
 
 func updateMovement() {
 	for {
-		time.Sleep(tickInterval)
-		if !Running {
+		time.Sleep(game.NextTickInterval(game.TickInterval))
+		if !Running || game.StepMode || view.GamePaused() {
 			continue
 		}
-		gui.Update(func(gui *gocui.Gui) error {
-			game.initPositionMatrix(gameView.position)
-			if err := hamiltonian_cycle.initHamiltonianCycle(gameView.position); err != nil {
-				log.Panicln(err)
-			}
-			if AutoPilotEnabled {
-				err := autopilot.autopilot()
-				if err != nil {
-					log.Panicln(err)
-				}
+		gui.Update(tick)
+	}
+}
+
+func tick(gui *gocui.Gui) error {
+	if !Running || view.GamePaused() {
+		return nil
+	}
+	lastDecision := advance()
+	view.GameBoard.Resize(gameView.Name, positionMatrix)
+	if err := renderer.Render(game.CurrentState(view.GameBoard.Size())); err != nil {
+		log.Panicln(err)
+	}
+	if err := view.UpdateStepView(game.Tick, lastDecision, game.StepMode); err != nil {
+		log.Panicln(err)
+	}
+	return nil
+}
+
+//Moves the game forward by one tick and returns how the move was decided.
+func advance() string {
+	game.TickInterval = game.CurveTickInterval()
+	game.RecordTick()
+	game.initPositionMatrix(gameView.position)
+	if err := hamiltonian_cycle.initHamiltonianCycle(gameView.position); err != nil {
+		log.Panicln(err)
+	}
+	game.ApplyQueuedDirection()
+	lastDecision := "human"
+	if AutoPilotEnabled {
+		err := autopilot.autopilot()
+		if err != nil {
+			log.Panicln(err)
+		}
+		lastDecision = autopilot.LastDecision.String()
+	}
+	if err := autopilot.UpdateStat(AutoPilotEnabled); err != nil {
+		log.Panicln(err)
+	}
+	if err := game.movesnakeHead(); err != nil {
+		log.Panicln(err)
+	}
+	if err := game.movesnakeBodyParts(); err != nil {
+		log.Panicln(err)
+	}
+	game.UpdateHazards(positionMatrix)
+	game.UpdateFoods(positionMatrix)
+	if err := game.UpdatePowerUps(positionMatrix); err != nil {
+		log.Panicln(err)
+	}
+	cleared, err := game.UpdateStage(gui)
+	if err != nil {
+		log.Panicln(err)
+	}
+	arenaOver, err := game.UpdateArena(gui, positionMatrix)
+	if err != nil {
+		log.Panicln(err)
+	}
+	if err := game.UpdateStats(); err != nil {
+		log.Panicln(err)
+	}
+	if cleared || arenaOver {
+		GameFinished = true
+		Running = false
+	}
+	return lastDecision
+}
+
+//Runs the game without gocui, drawing the frames with the ansi or text renderer.
+//Only the ansi renderer reads input, the text renderer is meant for autopilot runs.
+func runHeadless(cfg config.Config) error {
+	headlessRenderer, err := render.New(cfg.Renderer, os.Stdout)
+	if err != nil {
+		return err
+	}
+	//Steers from the spectators are applied by the game loop, and dropped once it has stopped reading them
+	remoteSteers := make(chan func())
+	done := make(chan struct{})
+	renderer, err = withSpectator(cfg, withRecorder(cfg, headlessRenderer), func(snake int, direction game.Direction) {
+		select {
+		case remoteSteers <- func() { game.Steer(snake, direction) }:
+		case <-done:
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer renderer.Close()
+	defer close(done)
+
+	gameView.Position = game.Position{X0: 0, Y0: 0, X1: cfg.BoardCols * game.DeltaX, Y1: cfg.BoardRows * game.DeltaY}
+	positionMatrix = game.GeneratePositionMatrix(gameView.Position)
+	game.Reseed(0)
+	game.snakeHead.position = view.GetRandomPosition(positionMatrix)
+	game.ResetFoods(positionMatrix)
+	if err := game.StartStage(positionMatrix); err != nil {
+		return err
+	}
+
+	keys := make(chan string)
+	if cfg.Renderer == render.ANSIName {
+		restore, err := render.RawMode()
+		if err != nil {
+			return err
+		}
+		defer restore()
+		go render.ReadKeys(os.Stdin, keys)
+	}
+
+	nextTick := time.After(game.NextTickInterval(game.TickInterval))
+	for !game.RunOver() {
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				keys = nil
+			} else if handleHeadlessKey(key) {
+				return nil
 			}
-			if err := game.movesnakeHead(); err != nil {
-				log.Panicln(err)
+		case steer := <-remoteSteers:
+			steer()
+		case <-nextTick:
+			nextTick = time.After(game.NextTickInterval(game.TickInterval))
+			if !Running {
+				continue
 			}
-			if err := game.movesnakeBodyParts(); err != nil {
-				log.Panicln(err)
+			advance()
+			if err := renderer.Render(game.CurrentState(cfg.BoardCols, cfg.BoardRows)); err != nil {
+				return err
 			}
-			return nil
-		})
+		}
+	}
+	return nil
+}
+
+//Adds the web spectator server to renderer if an HTTP address is configured.
+//Spectators and bots only get to steer the snakes through control if control mode is enabled.
+func withSpectator(cfg config.Config, renderer render.Renderer, control func(snake int, direction game.Direction)) (render.Renderer, error) {
+	if cfg.HTTPAddress == "" {
+		return renderer, nil
+	}
+	if !cfg.HTTPControl {
+		control = nil
+	}
+	server, err := spectator.Start(cfg.HTTPAddress, control)
+	if err != nil {
+		return nil, err
+	}
+	return render.Multi{renderer, server}, nil
+}
+
+//Adds a recorder of the last frames of the current run to renderer, so its replay can be saved from the game over screen,
+//and a recorder of the whole game if a record path is configured.
+func withRecorder(cfg config.Config, renderer render.Renderer) render.Renderer {
+	runRecorder := replay.NewCappedRecorder(runReplayFrames)
+	game.Restarted = runRecorder.Reset
+	game.SaveReplay = func() (string, error) {
+		path := filepath.Join(filepath.Dir(cfg.RecordPath), time.Now().Format("snake-20060102-150405.json"))
+		return path, runRecorder.Save(path)
+	}
+	if cfg.RecordPath == "" {
+		return render.Multi{renderer, runRecorder}
+	}
+	return render.Multi{renderer, runRecorder, replay.NewRecorder(cfg.RecordPath)}
+}
+
+//Handles a key press in headless mode, returning true if the game should quit.
+func handleHeadlessKey(key string) bool {
+	action, exist := game.ActionForKey(key)
+	if !exist {
+		return false
+	}
+	switch action {
+	case game.ActionQuit:
+		return true
+	case game.ActionUp:
+		game.QueueDirection(game.Directions.Up)
+	case game.ActionRight:
+		game.QueueDirection(game.Directions.Right)
+	case game.ActionDown:
+		game.QueueDirection(game.Directions.Down)
+	case game.ActionLeft:
+		game.QueueDirection(game.Directions.Left)
+	case game.ActionPause:
+		Running = !Running
+	case game.ActionAutopilot:
+		AutoPilotEnabled = !AutoPilotEnabled
+	case game.ActionSpeedUp:
+		game.AdjustSpeed(true)
+	case game.ActionSlowDown:
+		game.AdjustSpeed(false)
 	}
+	return false
 }
@@ -0,0 +1,133 @@
+package maze
+
+import "github.com/eiba/snake/game"
+
+//A 2 by 2 square of cells, the smallest piece of floor that can be walked around
+type square struct {
+	col int
+	row int
+}
+
+type edge [2]game.Cell
+
+func newEdge(a game.Cell, b game.Cell) edge {
+	if a.Col > b.Col || (a.Col == b.Col && a.Row > b.Row) {
+		a, b = b, a
+	}
+	return edge{a, b}
+}
+
+func (s square) cell(col int, row int) game.Cell {
+	return game.Cell{Col: 2*s.col + col, Row: 2*s.row + row}
+}
+
+//Cycle looks for a Hamiltonian cycle through the floor cells of any board, such as a hand-made stage. It only finds
+//one if the floor can be split into 2 by 2 squares that are all connected, returning false otherwise.
+func Cycle(floorCells map[game.Cell]bool) ([]game.Cell, bool) {
+	if len(floorCells) == 0 {
+		return nil, false
+	}
+	for _, offset := range []game.Cell{{Col: 0, Row: 0}, {Col: 1, Row: 0}, {Col: 0, Row: 1}, {Col: 1, Row: 1}} {
+		shifted := make(map[game.Cell]bool)
+		for cell := range floorCells {
+			shifted[game.Cell{Col: cell.Col + offset.Col, Row: cell.Row + offset.Row}] = true
+		}
+		if !tiledBySquares(shifted) {
+			continue
+		}
+		cycle := hamiltonianCycle(shifted)
+		if len(cycle) != len(floorCells) {
+			return nil, false
+		}
+		for i := range cycle {
+			cycle[i].Col -= offset.Col
+			cycle[i].Row -= offset.Row
+		}
+		return cycle, true
+	}
+	return nil, false
+}
+
+func tiledBySquares(floorCells map[game.Cell]bool) bool {
+	for cell := range floorCells {
+		s := square{cell.Col / 2, cell.Row / 2}
+		if !floorCells[s.cell(0, 0)] || !floorCells[s.cell(1, 0)] || !floorCells[s.cell(0, 1)] || !floorCells[s.cell(1, 1)] {
+			return false
+		}
+	}
+	return true
+}
+
+//Builds a Hamiltonian cycle through the floor cells by walking around a spanning tree of its 2 by 2 squares.
+//Every square starts as its own small loop, and every tree edge joins the loops of two neighbouring squares into one,
+//so the tree's loops end up as a single cycle. The floor must be made of whole squares that are all connected.
+func hamiltonianCycle(floorCells map[game.Cell]bool) []game.Cell {
+	squares := make(map[square]bool)
+	first := game.Cell{Col: -1}
+	for cell := range floorCells {
+		squares[square{cell.Col / 2, cell.Row / 2}] = true
+		if first.Col < 0 || cell.Col < first.Col || (cell.Col == first.Col && cell.Row < first.Row) {
+			first = cell
+		}
+	}
+
+	edges := make(map[edge]bool)
+	for s := range squares {
+		edges[newEdge(s.cell(0, 0), s.cell(1, 0))] = true
+		edges[newEdge(s.cell(1, 0), s.cell(1, 1))] = true
+		edges[newEdge(s.cell(1, 1), s.cell(0, 1))] = true
+		edges[newEdge(s.cell(0, 1), s.cell(0, 0))] = true
+	}
+
+	//Breadth-first spanning tree, only the edges to the right and downwards need joining
+	root := square{first.Col / 2, first.Row / 2}
+	visited := map[square]bool{root: true}
+	queue := []square{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range []square{{current.col, current.row - 1}, {current.col + 1, current.row}, {current.col, current.row + 1}, {current.col - 1, current.row}} {
+			if !squares[next] || visited[next] {
+				continue
+			}
+			visited[next] = true
+			queue = append(queue, next)
+			joinSquares(edges, current, next)
+		}
+	}
+
+	neighbourCells := make(map[game.Cell][]game.Cell)
+	for e := range edges {
+		neighbourCells[e[0]] = append(neighbourCells[e[0]], e[1])
+		neighbourCells[e[1]] = append(neighbourCells[e[1]], e[0])
+	}
+	cycle := []game.Cell{first}
+	previous, current := first, neighbourCells[first][0]
+	for current != first {
+		cycle = append(cycle, current)
+		next := neighbourCells[current][0]
+		if next == previous {
+			next = neighbourCells[current][1]
+		}
+		previous, current = current, next
+	}
+	return cycle
+}
+
+//Replaces the facing sides of two neighbouring squares with two edges crossing between them.
+func joinSquares(edges map[edge]bool, a square, b square) {
+	if b.col < a.col || b.row < a.row {
+		a, b = b, a
+	}
+	if b.col > a.col {
+		delete(edges, newEdge(a.cell(1, 0), a.cell(1, 1)))
+		delete(edges, newEdge(b.cell(0, 0), b.cell(0, 1)))
+		edges[newEdge(a.cell(1, 0), b.cell(0, 0))] = true
+		edges[newEdge(a.cell(1, 1), b.cell(0, 1))] = true
+		return
+	}
+	delete(edges, newEdge(a.cell(0, 1), a.cell(1, 1)))
+	delete(edges, newEdge(b.cell(0, 0), b.cell(1, 0)))
+	edges[newEdge(a.cell(0, 1), b.cell(0, 0))] = true
+	edges[newEdge(a.cell(1, 1), b.cell(1, 0))] = true
+}
@@ -0,0 +1,211 @@
+package maze
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game"
+	"math/rand"
+	"time"
+)
+
+const (
+	MazeStyle  = "maze"
+	RoomsStyle = "rooms"
+)
+
+type Options struct {
+	Style string
+	//Seed of the layout, the same seed and options always give the same layout
+	Seed int64
+	Cols int
+	Rows int
+	//How much of the board is wall, from 0 to 1
+	Density float64
+	//Narrowest passage in cells, rounded up to an even number
+	CorridorWidth int
+	//Starting tick interval of the speed curve
+	Speed time.Duration
+}
+
+//A block of CorridorWidth by CorridorWidth cells, the unit walls and floor are laid out in
+type block struct {
+	col int
+	row int
+}
+
+//Generate builds a board from the options. The floor is made of whole blocks of at least 2 by 2 cells that are all
+//connected, which guarantees that every floor cell is reachable and that the floor has a Hamiltonian cycle,
+//returned as the stage's cycle for the autopilot.
+func Generate(options Options) (*game.Stage, error) {
+	blockSize := options.CorridorWidth
+	if blockSize < 2 {
+		blockSize = 2
+	}
+	blockSize += blockSize % 2
+	blockCols, blockRows := options.Cols/blockSize, options.Rows/blockSize
+	if blockCols < 1 || blockRows < 1 || blockCols*blockRows < 2 {
+		return nil, fmt.Errorf("a %dx%d board is too small for corridors of width %d", options.Cols, options.Rows, blockSize)
+	}
+	if options.Density < 0 || options.Density > 1 {
+		return nil, fmt.Errorf("density must be between 0 and 1")
+	}
+
+	r := rand.New(rand.NewSource(options.Seed))
+	var floor map[block]bool
+	switch options.Style {
+	case MazeStyle:
+		floor = generateMaze(r, blockCols, blockRows, options.Density)
+	case RoomsStyle:
+		floor = generateRooms(r, blockCols, blockRows, options.Density)
+	default:
+		return nil, fmt.Errorf("unknown maze style %q", options.Style)
+	}
+
+	floorCells := make(map[game.Cell]bool)
+	for b := range floor {
+		for col := b.col * blockSize; col < (b.col+1)*blockSize; col++ {
+			for row := b.row * blockSize; row < (b.row+1)*blockSize; row++ {
+				floorCells[game.Cell{Col: col, Row: row}] = true
+			}
+		}
+	}
+	stage := &game.Stage{
+		Name:        fmt.Sprintf("%v %d", options.Style, options.Seed),
+		Cols:        options.Cols,
+		Rows:        options.Rows,
+		StartLength: 1,
+		Speed:       options.Speed,
+		Goal:        game.Goal{Kind: game.GoalKinds.Endless},
+		Cycle:       hamiltonianCycle(floorCells),
+	}
+	//Start on the cycle, heading along it
+	stage.HasStart = true
+	stage.Start = stage.Cycle[0]
+	stage.StartDirection = game.Directions.Right
+	if stage.Cycle[1].Col == stage.Start.Col {
+		stage.StartDirection = game.Directions.Down
+	}
+	for col := 0; col < options.Cols; col++ {
+		for row := 0; row < options.Rows; row++ {
+			if cell := (game.Cell{Col: col, Row: row}); !floorCells[cell] {
+				stage.Walls = append(stage.Walls, cell)
+			}
+		}
+	}
+	return stage, nil
+}
+
+func neighbours(b block) []block {
+	return []block{{b.col, b.row - 1}, {b.col + 1, b.row}, {b.col, b.row + 1}, {b.col - 1, b.row}}
+}
+
+func inside(b block, cols int, rows int) bool {
+	return b.col >= 0 && b.col < cols && b.row >= 0 && b.row < rows
+}
+
+//Carves a maze with a randomized depth-first search between the blocks at even coordinates, the blocks in between
+//being the passages. The lower the density, the more of the remaining walls between passages are knocked out.
+func generateMaze(r *rand.Rand, cols int, rows int, density float64) map[block]bool {
+	floor := map[block]bool{{0, 0}: true}
+	stack := []block{{0, 0}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		var unvisited []block
+		for _, next := range []block{{current.col, current.row - 2}, {current.col + 2, current.row}, {current.col, current.row + 2}, {current.col - 2, current.row}} {
+			if inside(next, cols, rows) && !floor[next] {
+				unvisited = append(unvisited, next)
+			}
+		}
+		if len(unvisited) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		next := unvisited[r.Intn(len(unvisited))]
+		floor[block{(current.col + next.col) / 2, (current.row + next.row) / 2}] = true
+		floor[next] = true
+		stack = append(stack, next)
+	}
+
+	//Walls between two passages, which can be removed without cutting anything off
+	for col := 0; col < cols; col++ {
+		for row := 0; row < rows; row++ {
+			b := block{col, row}
+			if floor[b] || (col%2 == 0) == (row%2 == 0) {
+				continue
+			}
+			if r.Float64() >= density {
+				floor[b] = true
+			}
+		}
+	}
+	return floor
+}
+
+type room struct {
+	col  int
+	row  int
+	cols int
+	rows int
+}
+
+func (rm room) center() block {
+	return block{rm.col + rm.cols/2, rm.row + rm.rows/2}
+}
+
+//Places random rooms and joins each room to the previous one with an L-shaped corridor, until the share of floor
+//reaches 1 - density.
+func generateRooms(r *rand.Rand, cols int, rows int, density float64) map[block]bool {
+	floor := make(map[block]bool)
+	target := int((1 - density) * float64(cols*rows))
+	if target < 2 {
+		target = 2
+	}
+	var previous *room
+	for attempt := 0; attempt < 100 && len(floor) < target; attempt++ {
+		rm := room{cols: 1 + r.Intn(max(1, cols/3)), rows: 1 + r.Intn(max(1, rows/3))}
+		rm.col = r.Intn(cols - rm.cols + 1)
+		rm.row = r.Intn(rows - rm.rows + 1)
+		for col := rm.col; col < rm.col+rm.cols; col++ {
+			for row := rm.row; row < rm.row+rm.rows; row++ {
+				floor[block{col, row}] = true
+			}
+		}
+		if previous != nil {
+			addCorridor(r, floor, previous.center(), rm.center())
+		}
+		previous = &rm
+	}
+	if len(floor) < 2 {
+		floor[block{0, 0}] = true
+		floor[block{1 % cols, 1 / cols}] = true
+	}
+	return floor
+}
+
+func addCorridor(r *rand.Rand, floor map[block]bool, from block, to block) {
+	corner := block{to.col, from.row}
+	if r.Intn(2) == 0 {
+		corner = block{from.col, to.row}
+	}
+	for _, segment := range [][2]block{{from, corner}, {corner, to}} {
+		start, end := segment[0], segment[1]
+		for col := min(start.col, end.col); col <= max(start.col, end.col); col++ {
+			for row := min(start.row, end.row); row <= max(start.row, end.row); row++ {
+				floor[block{col, row}] = true
+			}
+		}
+	}
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,274 @@
+package menu
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/config"
+	"github.com/eiba/snake/game/view"
+	"strings"
+)
+
+type Choice int
+type choices struct {
+	Play     Choice
+	Versus   Choice
+	Campaign Choice
+	Quit     Choice
+}
+
+type screen int
+type screens struct {
+	Main        screen
+	Settings    screen
+	Replays     screen
+	Leaderboard screen
+	Playback    screen
+}
+
+const (
+	listViewName   = "menu"
+	helpViewName   = "menuHelp"
+	replayViewName = "menuReplay"
+	minListWidth   = 30
+)
+
+var (
+	Choices = choices{0, 1, 2, 3}
+	Screens = screens{0, 1, 2, 3, 4}
+	//Entries of the main menu in the order they are listed
+	mainItems = []struct {
+		label  string
+		choose func(gui *gocui.Gui) error
+	}{
+		{"Play", choose(Choices.Play)},
+		{"Versus", choose(Choices.Versus)},
+		{"Campaign", choose(Choices.Campaign)},
+		{"Replays", func(gui *gocui.Gui) error { return openReplays() }},
+		{"Leaderboard", func(gui *gocui.Gui) error { return openLeaderboard() }},
+		{"Settings", func(gui *gocui.Gui) error {
+			openSettings()
+			return nil
+		}},
+		{"Quit", choose(Choices.Quit)},
+	}
+	cfg           config.Config
+	choice        = Choices.Quit
+	currentScreen = Screens.Main
+	selected      = 0
+	//The main menu entry that was selected when another screen was opened
+	mainSelected = 0
+	message      = ""
+)
+
+//Run shows the main menu until a game is chosen or the menu is quit. It returns the choice and the configuration
+//with the changes made on the settings screen.
+func Run(c config.Config) (Choice, config.Config, error) {
+	cfg = c
+	choice = Choices.Quit
+	currentScreen = Screens.Main
+	selected = 0
+	mainSelected = 0
+
+	gui, err := gocui.NewGui(view.DetectOutputMode(), true)
+	if err != nil {
+		return Choices.Quit, cfg, err
+	}
+	defer gui.Close()
+	gui.SetManagerFunc(layout)
+	if err := initKeybindings(gui); err != nil {
+		return Choices.Quit, cfg, err
+	}
+	if err := gui.MainLoop(); err != nil && !gocui.IsQuit(err) {
+		return Choices.Quit, cfg, err
+	}
+	stopPlayback()
+	return choice, cfg, nil
+}
+
+func choose(c Choice) func(gui *gocui.Gui) error {
+	return func(gui *gocui.Gui) error {
+		choice = c
+		return gocui.ErrQuit
+	}
+}
+
+//Switches to s with the first line selected.
+func open(s screen) {
+	if currentScreen == Screens.Main {
+		mainSelected = selected
+	}
+	currentScreen = s
+	selected = 0
+	message = ""
+}
+
+func backToMain() {
+	currentScreen = Screens.Main
+	selected = mainSelected
+	message = ""
+}
+
+//Returns the title of the current screen, its lines and whether a line can be selected.
+func screenLines() (string, []string, bool) {
+	switch currentScreen {
+	case Screens.Settings:
+		return "Settings", settingsLines(), true
+	case Screens.Replays:
+		return "Replays", replayLines(), len(replayPaths) > 0
+	case Screens.Leaderboard:
+		return "Leaderboard", leaderboardLines, false
+	}
+	lines := make([]string, len(mainItems))
+	for i, item := range mainItems {
+		lines[i] = item.label
+	}
+	return "snake", lines, true
+}
+
+func screenHelp() string {
+	switch currentScreen {
+	case Screens.Settings:
+		return "↑↓: Select, ←→: Change, Enter: Save, Esc: Back"
+	case Screens.Replays:
+		return "↑↓: Select, Enter: Watch, Esc: Back"
+	case Screens.Leaderboard:
+		return "Esc: Back"
+	case Screens.Playback:
+		return playbackStatus()
+	}
+	return "↑↓: Select, Enter: Open, Esc: Quit"
+}
+
+func layout(gui *gocui.Gui) error {
+	maxX, maxY := gui.Size()
+	if currentScreen == Screens.Playback {
+		if err := deleteView(gui, listViewName); err != nil {
+			return err
+		}
+		if err := layoutPlayback(gui); err != nil {
+			return err
+		}
+		return setHelpView(gui, 0, maxY-3, maxX-1, maxY-1)
+	}
+	if err := deleteView(gui, replayViewName); err != nil {
+		return err
+	}
+
+	title, lines, selectable := screenLines()
+	width := minListWidth
+	for _, line := range lines {
+		if len([]rune(line))+2 > width {
+			width = len([]rune(line)) + 2
+		}
+	}
+	height := len(lines) + 1
+	x0, y0 := (maxX-width)/2, (maxY-height-3)/2
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	v, err := gui.SetView(listViewName, x0, y0, x0+width, y0+height, 0)
+	if err != nil {
+		if !gocui.IsUnknownView(err) {
+			return err
+		}
+		v.SelBgColor = gocui.ColorGreen
+		v.SelFgColor = gocui.ColorBlack
+		if _, err := gui.SetCurrentView(listViewName); err != nil {
+			return err
+		}
+	}
+	v.Title = title
+	v.Highlight = selectable
+	v.Clear()
+	fmt.Fprint(v, strings.Join(lines, "\n"))
+	if err := v.SetCursor(0, selected); err != nil {
+		return err
+	}
+	return setHelpView(gui, x0, y0+height+1, x0+width, y0+height+3)
+}
+
+func setHelpView(gui *gocui.Gui, x0 int, y0 int, x1 int, y1 int) error {
+	v, err := gui.SetView(helpViewName, x0, y0, x1, y1, 0)
+	if err != nil && !gocui.IsUnknownView(err) {
+		return err
+	}
+	v.Frame = false
+	v.Clear()
+	if message != "" {
+		fmt.Fprint(v, message)
+		return nil
+	}
+	fmt.Fprint(v, screenHelp())
+	return nil
+}
+
+func deleteView(gui *gocui.Gui, name string) error {
+	if err := gui.DeleteView(name); err != nil && !gocui.IsUnknownView(err) {
+		return err
+	}
+	return nil
+}
+
+func initKeybindings(gui *gocui.Gui) error {
+	bindings := []struct {
+		key     interface{}
+		handler func(gui *gocui.Gui, v *gocui.View) error
+	}{
+		{gocui.KeyArrowUp, func(gui *gocui.Gui, v *gocui.View) error { return moveSelection(-1) }},
+		{gocui.KeyArrowDown, func(gui *gocui.Gui, v *gocui.View) error { return moveSelection(1) }},
+		{gocui.KeyArrowLeft, func(gui *gocui.Gui, v *gocui.View) error { return changeSetting(-1) }},
+		{gocui.KeyArrowRight, func(gui *gocui.Gui, v *gocui.View) error { return changeSetting(1) }},
+		{gocui.KeyEnter, enter},
+		{gocui.KeyEsc, back},
+		{gocui.KeyCtrlC, func(gui *gocui.Gui, v *gocui.View) error { return choose(Choices.Quit)(gui) }},
+	}
+	for _, binding := range bindings {
+		if err := gui.SetKeybinding("", binding.key, gocui.ModNone, binding.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func moveSelection(offset int) error {
+	_, lines, selectable := screenLines()
+	if !selectable || currentScreen == Screens.Playback {
+		return nil
+	}
+	selected = (selected + offset + len(lines)) % len(lines)
+	message = ""
+	return nil
+}
+
+func enter(gui *gocui.Gui, v *gocui.View) error {
+	switch currentScreen {
+	case Screens.Main:
+		return mainItems[selected].choose(gui)
+	case Screens.Settings:
+		saveSettings()
+	case Screens.Replays:
+		watchReplay(gui)
+	}
+	return nil
+}
+
+func back(gui *gocui.Gui, v *gocui.View) error {
+	switch currentScreen {
+	case Screens.Main:
+		return choose(Choices.Quit)(gui)
+	case Screens.Settings:
+		if leaveSettings() {
+			backToMain()
+		}
+	case Screens.Playback:
+		stopPlayback()
+		currentScreen = Screens.Replays
+		message = ""
+	default:
+		backToMain()
+	}
+	return nil
+}
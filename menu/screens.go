@@ -0,0 +1,136 @@
+package menu
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/game/view"
+	"github.com/eiba/snake/highscore"
+	"github.com/eiba/snake/replay"
+	"path/filepath"
+	"time"
+)
+
+var (
+	leaderboardLines []string
+	replayDirectory  = "."
+	replayPaths      []string
+	playback         replay.Replay
+	playbackFrame    = 0
+	//Closed to stop the running playback
+	stopPlaying chan bool
+)
+
+func openLeaderboard() error {
+	entries, err := highscore.Load()
+	if err != nil {
+		return err
+	}
+	open(Screens.Leaderboard)
+	leaderboardLines = []string{}
+	for i, entry := range entries {
+		leaderboardLines = append(leaderboardLines, fmt.Sprintf("%2d. %v", i+1, entry))
+	}
+	if len(leaderboardLines) == 0 {
+		leaderboardLines = append(leaderboardLines, "No runs yet")
+	}
+	return nil
+}
+
+//Lists the replays in the directory replays are recorded to, or in the working directory if none is configured.
+func openReplays() error {
+	replayDirectory = "."
+	if cfg.RecordPath != "" {
+		replayDirectory = filepath.Dir(cfg.RecordPath)
+	}
+	paths, err := filepath.Glob(filepath.Join(replayDirectory, "*.json"))
+	if err != nil {
+		return err
+	}
+	open(Screens.Replays)
+	replayPaths = paths
+	return nil
+}
+
+func replayLines() []string {
+	if len(replayPaths) == 0 {
+		return []string{fmt.Sprintf("No replays in %v", replayDirectory), "Record one with -record replay.json"}
+	}
+	lines := make([]string, len(replayPaths))
+	for i, path := range replayPaths {
+		lines[i] = filepath.Base(path)
+	}
+	return lines
+}
+
+//Starts playing back the selected replay, frame by frame at the speed it was recorded at.
+func watchReplay(gui *gocui.Gui) {
+	if len(replayPaths) == 0 {
+		return
+	}
+	r, err := replay.Load(replayPaths[selected])
+	if err != nil {
+		message = err.Error()
+		return
+	}
+	if len(r.Frames) == 0 {
+		message = "The replay has no frames"
+		return
+	}
+	playback = r
+	playbackFrame = 0
+	currentScreen = Screens.Playback
+	message = ""
+	stopPlaying = make(chan bool)
+	go play(gui, r.Frames, stopPlaying)
+}
+
+//Advances the shown frame once the previous frame's time is up, until the last frame or until stop is closed.
+func play(gui *gocui.Gui, frames []game.State, stop chan bool) {
+	for i := 1; i < len(frames); i++ {
+		select {
+		case <-stop:
+			return
+		case <-time.After(frames[i-1].TickInterval):
+		}
+		frame := i
+		gui.Update(func(gui *gocui.Gui) error {
+			select {
+			case <-stop:
+			default:
+				playbackFrame = frame
+			}
+			return nil
+		})
+	}
+}
+
+func stopPlayback() {
+	if stopPlaying != nil {
+		close(stopPlaying)
+		stopPlaying = nil
+	}
+}
+
+func playbackStatus() string {
+	return fmt.Sprintf("%v: frame %d/%d, Esc: Back", filepath.Base(replayPaths[selected]), playbackFrame+1, len(playback.Frames))
+}
+
+func layoutPlayback(gui *gocui.Gui) error {
+	frame := playback.Frames[playbackFrame]
+	boardPosition := game.Position{X0: 0, Y0: 0, X1: frame.Cols * game.DeltaX, Y1: frame.Rows * game.DeltaY}
+	view.GameBoard.Resize(replayViewName, game.GeneratePositionMatrix(boardPosition))
+	if v, err := gui.SetView(replayViewName, boardPosition.X0, boardPosition.Y0, boardPosition.X1, boardPosition.Y1, 0); err != nil {
+		if !gocui.IsUnknownView(err) {
+			return err
+		}
+		v.Title = "Replay"
+		v.BgColor = view.BackgroundColor()
+		if _, err := gui.SetCurrentView(replayViewName); err != nil {
+			return err
+		}
+		//The view is new, so nothing of an earlier playback is on it
+		view.GameBoard.Invalidate()
+	}
+	return game.DrawState(gui, frame)
+}
@@ -0,0 +1,185 @@
+package menu
+
+import (
+	"fmt"
+	"github.com/eiba/snake/config"
+	"time"
+)
+
+//A line of the settings screen: the setting's name, its value as shown and how the arrow keys change it
+type option struct {
+	name   string
+	value  func(c *config.Config) string
+	change func(c *config.Config, offset int)
+}
+
+const (
+	speedChange    = 10 * time.Millisecond
+	maxSpeed       = 500 * time.Millisecond
+	minBoardSize   = 10
+	maxBoardSize   = 200
+	discardMessage = "Unsaved changes, press Esc again to discard them"
+)
+
+var (
+	difficultyNames = []string{"easy", "normal", "hard", "insane"}
+	themeNames      = []string{"dark", "light", "high-contrast", "monochrome"}
+	presetNames     = []string{"arrows", "vim", "wasd"}
+	strategyNames   = []string{"greedy", "cycle"}
+	options         = []option{
+		{"Difficulty", func(c *config.Config) string { return c.Difficulty }, func(c *config.Config, offset int) {
+			c.Difficulty = cycle(difficultyNames, c.Difficulty, offset)
+		}},
+		{"Start speed", func(c *config.Config) string {
+			if c.TickInterval.Duration == 0 {
+				return "difficulty's"
+			}
+			return c.TickInterval.String()
+		}, func(c *config.Config, offset int) {
+			c.TickInterval.Duration = clampDuration(c.TickInterval.Duration+time.Duration(offset)*speedChange, 0, maxSpeed)
+		}},
+		{"Fit terminal", func(c *config.Config) string { return onOff(c.FitTerminal) }, func(c *config.Config, offset int) {
+			c.FitTerminal = !c.FitTerminal
+		}},
+		{"Board width", func(c *config.Config) string { return fmt.Sprint(c.BoardCols) }, func(c *config.Config, offset int) {
+			c.BoardCols = clamp(c.BoardCols+offset, minBoardSize, maxBoardSize)
+		}},
+		{"Board height", func(c *config.Config) string { return fmt.Sprint(c.BoardRows) }, func(c *config.Config, offset int) {
+			c.BoardRows = clamp(c.BoardRows+offset, minBoardSize, maxBoardSize)
+		}},
+		{"Theme", func(c *config.Config) string { return c.Theme }, func(c *config.Config, offset int) {
+			c.Theme = cycle(themeNames, c.Theme, offset)
+		}},
+		{"Wrap", func(c *config.Config) string { return onOff(c.Wrap) }, func(c *config.Config, offset int) {
+			c.Wrap = !c.Wrap
+		}},
+		{"Keys", func(c *config.Config) string { return c.Keybindings.Preset }, func(c *config.Config, offset int) {
+			c.Keybindings.Preset = cycle(presetNames, c.Keybindings.Preset, offset)
+		}},
+		{"Autopilot", func(c *config.Config) string { return onOff(c.AutoPilot) }, func(c *config.Config, offset int) {
+			c.AutoPilot = !c.AutoPilot
+		}},
+		{"Strategy", func(c *config.Config) string { return c.Strategy }, func(c *config.Config, offset int) {
+			c.Strategy = cycle(strategyNames, c.Strategy, offset)
+		}},
+	}
+	//The settings as they were when the settings screen was opened or last saved
+	savedSettings   config.Config
+	settingsChanged = false
+)
+
+func openSettings() {
+	open(Screens.Settings)
+	savedSettings = cfg
+	settingsChanged = false
+}
+
+func settingsLines() []string {
+	lines := make([]string, len(options))
+	for i, o := range options {
+		lines[i] = fmt.Sprintf("%-13v ← %v →", o.name, o.value(&cfg))
+	}
+	return lines
+}
+
+func changeSetting(offset int) error {
+	if currentScreen != Screens.Settings {
+		return nil
+	}
+	options[selected].change(&cfg, offset)
+	settingsChanged = true
+	message = ""
+	return nil
+}
+
+//Writes the settings changed on the screen to the config file, so they are used by every later game. The settings
+//that were not changed are left alone, as they may come from environment variables or flags.
+func saveSettings() {
+	err := config.Update(func(c *config.Config) {
+		if cfg.Difficulty != savedSettings.Difficulty {
+			c.Difficulty = cfg.Difficulty
+		}
+		if cfg.TickInterval != savedSettings.TickInterval {
+			c.TickInterval = cfg.TickInterval
+		}
+		if cfg.BoardCols != savedSettings.BoardCols {
+			c.BoardCols = cfg.BoardCols
+		}
+		if cfg.BoardRows != savedSettings.BoardRows {
+			c.BoardRows = cfg.BoardRows
+		}
+		if cfg.FitTerminal != savedSettings.FitTerminal {
+			c.FitTerminal = cfg.FitTerminal
+		}
+		if cfg.Theme != savedSettings.Theme {
+			c.Theme = cfg.Theme
+		}
+		if cfg.Wrap != savedSettings.Wrap {
+			c.Wrap = cfg.Wrap
+		}
+		if cfg.Keybindings.Preset != savedSettings.Keybindings.Preset {
+			c.Keybindings.Preset = cfg.Keybindings.Preset
+		}
+		if cfg.AutoPilot != savedSettings.AutoPilot {
+			c.AutoPilot = cfg.AutoPilot
+		}
+		if cfg.Strategy != savedSettings.Strategy {
+			c.Strategy = cfg.Strategy
+		}
+	})
+	if err != nil {
+		message = err.Error()
+		return
+	}
+	savedSettings = cfg
+	settingsChanged = false
+	message = "Saved"
+}
+
+//Returns true if the settings screen can be left. Unsaved changes are only discarded when Esc is pressed twice.
+func leaveSettings() bool {
+	if settingsChanged && message != discardMessage {
+		message = discardMessage
+		return false
+	}
+	cfg = savedSettings
+	return true
+}
+
+//Returns the value offset places after current in values, starting over at the other end.
+func cycle(values []string, current string, offset int) string {
+	index := 0
+	for i, value := range values {
+		if value == current {
+			index = i
+		}
+	}
+	return values[((index+offset)%len(values)+len(values))%len(values)]
+}
+
+func clamp(value int, min int, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func clampDuration(value time.Duration, min time.Duration, max time.Duration) time.Duration {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
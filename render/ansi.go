@@ -0,0 +1,104 @@
+package render
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/game/view"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	clearScreen = "\033[2J"
+	cursorHome  = "\033[H"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+	resetStyle  = "\033[0m"
+)
+
+//ANSI draws frames with plain escape sequences, so it works on any terminal without gocui.
+type ANSI struct {
+	w       io.Writer
+	started bool
+}
+
+func NewANSI(w io.Writer) *ANSI {
+	return &ANSI{w: w}
+}
+
+func (a *ANSI) Render(state game.State) error {
+	if !a.started {
+		if _, err := fmt.Fprint(a.w, clearScreen+hideCursor); err != nil {
+			return err
+		}
+		a.started = true
+	}
+	_, err := fmt.Fprint(a.w, cursorHome+ANSIFrame(state))
+	return err
+}
+
+func (a *ANSI) Close() error {
+	_, err := fmt.Fprint(a.w, resetStyle+showCursor+"\n")
+	return err
+}
+
+//ANSIFrame draws state as a framed board in the colours of the current theme, followed by a status line.
+func ANSIFrame(state game.State) string {
+	var builder strings.Builder
+	width := state.Cols * game.DeltaX
+	background := view.EscapeSequence(view.CellStyle{Glyph: ' ', Bg: view.BackgroundColor()})
+	contents := cellContents(state)
+
+	builder.WriteString("┌" + strings.Repeat("─", width) + "┐\r\n")
+	for row := 0; row < state.Rows; row++ {
+		for i := 0; i < game.DeltaY; i++ {
+			builder.WriteString("│")
+			for col := 0; col < state.Cols; col++ {
+				builder.WriteString(ansiCell(contents, game.Cell{Col: col, Row: row}, len(state.Snake), background, i == 0))
+			}
+			builder.WriteString(resetStyle + "│\r\n")
+		}
+	}
+	builder.WriteString("└" + strings.Repeat("─", width) + "┘\r\n")
+	builder.WriteString(fmt.Sprintf("Score: %-8d Length: %-6d Tick: %-8d Speed: %-6v", state.Score, len(state.Snake), state.Tick, state.TickInterval.Round(time.Millisecond)))
+	for _, name := range sortedEffects(state.Effects) {
+		builder.WriteString(fmt.Sprintf(" %v: %d", name, state.Effects[name]))
+	}
+	if state.RunOver {
+		builder.WriteString(" Game Over")
+	}
+	builder.WriteString("\033[K\r\n")
+	return builder.String()
+}
+
+func ansiCell(contents map[game.Cell]cellContent, cell game.Cell, length int, background string, withGlyph bool) string {
+	content, exist := contents[cell]
+	if !exist {
+		return background + strings.Repeat(" ", game.DeltaX)
+	}
+	var style view.CellStyle
+	switch {
+	case content.snakeIndex >= 0:
+		style = view.SnakeStyle(content.snakeIndex, length)
+	case content.wall:
+		style = view.WallStyle()
+	case content.warning:
+		style = view.WarningStyle()
+	case content.portal:
+		style = view.PortalStyle()
+	case content.hazard:
+		style = view.HazardStyle()
+	case content.enemy:
+		style = view.EnemyStyle(content.enemyIndex)
+	case content.powerUp != "":
+		style = view.PowerUpStyle(content.powerUp)
+	default:
+		style = view.FoodStyle(content.food)
+	}
+	glyph := " "
+	if withGlyph {
+		glyph = string(style.Glyph)
+	}
+	return view.EscapeSequence(style) + glyph + strings.Repeat(" ", game.DeltaX-1)
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var escapeKeyNames = map[string]string{
+	"[A": "ArrowUp",
+	"[B": "ArrowDown",
+	"[C": "ArrowRight",
+	"[D": "ArrowLeft",
+}
+
+var controlKeyNames = map[byte]string{
+	' ':  "Space",
+	'\t': "Tab",
+	'\r': "Enter",
+	'\n': "Enter",
+	127:  "Backspace",
+}
+
+//RawMode switches the terminal to unbuffered input without echo, using stty so no terminal library is needed.
+//The returned function restores the previous mode.
+func RawMode() (func() error, error) {
+	previous, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := stty(strings.TrimSpace(previous))
+		return err
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+//ReadKeys reads key presses from r and sends their names, in the format of the keybindings config, to keys.
+//It closes keys when r is exhausted.
+func ReadKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	reader := bufio.NewReader(r)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+		if b != 27 {
+			if name, exist := controlKeyNames[b]; exist {
+				keys <- name
+			} else {
+				keys <- string(rune(b))
+			}
+			continue
+		}
+		//An escape sequence arrives in one read, a lone escape key press does not have any bytes following it
+		if reader.Buffered() < 2 {
+			keys <- "Esc"
+			continue
+		}
+		sequence := make([]byte, 2)
+		if _, err := io.ReadFull(reader, sequence); err != nil {
+			return
+		}
+		if name, exist := escapeKeyNames[string(sequence)]; exist {
+			keys <- name
+		}
+	}
+}
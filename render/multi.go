@@ -0,0 +1,25 @@
+package render
+
+import "github.com/eiba/snake/game"
+
+//Multi renders every frame with each of its renderers, e.g. the terminal and the web spectators.
+type Multi []Renderer
+
+func (m Multi) Render(state game.State) error {
+	for _, renderer := range m {
+		if err := renderer.Render(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Multi) Close() error {
+	var firstErr error
+	for _, renderer := range m {
+		if err := renderer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
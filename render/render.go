@@ -0,0 +1,94 @@
+package render
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game"
+	"io"
+	"sort"
+)
+
+//Renderer draws frames of the game from the engine state.
+type Renderer interface {
+	Render(state game.State) error
+	Close() error
+}
+
+const (
+	TUIName  = "gocui"
+	ANSIName = "ansi"
+	TextName = "text"
+)
+
+//New creates the headless renderer called name, writing its frames to w.
+//The gocui renderer needs the gui and is created with NewTUI instead.
+func New(name string, w io.Writer) (Renderer, error) {
+	switch name {
+	case ANSIName:
+		return NewANSI(w), nil
+	case TextName:
+		return NewText(w), nil
+	}
+	return nil, fmt.Errorf("unknown renderer %q", name)
+}
+
+//What occupies a board cell: a snake segment, by its index from the head, a wall, which includes the closed rings
+//of the battle royale border, the flashing ring about to close, a portal of the given pair,
+//a food or power-up of the named type, a hazard or a segment of an enemy snake, by its index from the enemy's head
+type cellContent struct {
+	snakeIndex int
+	wall       bool
+	warning    bool
+	portal     bool
+	pair       int
+	food       string
+	powerUp    string
+	hazard     bool
+	enemy      bool
+	enemyIndex int
+}
+
+//Indexes the snake, the walls, the closing border, the portals, the foods, the power-ups and the hazards by cell.
+func cellContents(state game.State) map[game.Cell]cellContent {
+	contents := make(map[game.Cell]cellContent)
+	closed, warned := state.ArenaCells()
+	for _, cell := range state.Walls {
+		contents[cell] = cellContent{snakeIndex: -1, wall: true}
+	}
+	for _, cell := range closed {
+		contents[cell] = cellContent{snakeIndex: -1, wall: true}
+	}
+	for _, cell := range warned {
+		contents[cell] = cellContent{snakeIndex: -1, warning: true}
+	}
+	for _, p := range state.Portals {
+		contents[p.Cell] = cellContent{snakeIndex: -1, portal: true, pair: p.Pair}
+	}
+	for _, f := range state.Foods {
+		contents[f.Cell] = cellContent{snakeIndex: -1, food: f.Type}
+	}
+	for _, p := range state.PowerUps {
+		contents[p.Cell] = cellContent{snakeIndex: -1, powerUp: p.Type}
+	}
+	for _, cell := range state.Hazards {
+		contents[cell] = cellContent{snakeIndex: -1, hazard: true}
+	}
+	for _, enemy := range state.Enemies {
+		for i := len(enemy) - 1; i >= 0; i-- {
+			contents[enemy[i]] = cellContent{snakeIndex: -1, enemy: true, enemyIndex: i}
+		}
+	}
+	for i := len(state.Snake) - 1; i >= 0; i-- {
+		contents[state.Snake[i]] = cellContent{snakeIndex: i}
+	}
+	return contents
+}
+
+//Returns the names of the active effects in a stable order.
+func sortedEffects(effects map[string]int) []string {
+	names := make([]string, 0, len(effects))
+	for name := range effects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
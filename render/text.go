@@ -0,0 +1,85 @@
+package render
+
+import (
+	"fmt"
+	"github.com/eiba/snake/game"
+	"io"
+	"strings"
+)
+
+//Text writes every frame as plain text, for logs and tests.
+type Text struct {
+	w io.Writer
+}
+
+func NewText(w io.Writer) *Text {
+	return &Text{w}
+}
+
+func (t *Text) Render(state game.State) error {
+	_, err := fmt.Fprintln(t.w, TextFrame(state))
+	return err
+}
+
+func (t *Text) Close() error {
+	return nil
+}
+
+//Characters of the food types in text frames
+var textFoodGlyphs = map[string]string{
+	"regular": "*",
+	"golden":  "$",
+	"growth":  "+",
+	"poison":  "!",
+}
+
+//Characters of the power-up types in text frames
+var textPowerUpGlyphs = map[string]string{
+	"slow-motion": "S",
+	"ghost":       "G",
+	"shrink":      "X",
+}
+
+//TextFrame draws state with one character per cell: @ for the head, o for the body, # for walls and the closed
+//border, ! for the ring about to close, a letter per
+//portal pair, the food and power-up characters for food and power-ups, % for hazards, E and e for the heads and bodies
+//of enemy snakes and . for empty cells.
+func TextFrame(state game.State) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("tick %d length %d score %d", state.Tick, len(state.Snake), state.Score))
+	if state.RunOver {
+		builder.WriteString(" game over")
+	}
+	contents := cellContents(state)
+	for row := 0; row < state.Rows; row++ {
+		builder.WriteString("\n")
+		for col := 0; col < state.Cols; col++ {
+			content, exist := contents[game.Cell{Col: col, Row: row}]
+			switch {
+			case !exist:
+				builder.WriteString(".")
+			case content.wall:
+				builder.WriteString("#")
+			case content.warning:
+				builder.WriteString("!")
+			case content.portal:
+				builder.WriteRune(rune('a' + content.pair%26))
+			case content.hazard:
+				builder.WriteString("%")
+			case content.enemy && content.enemyIndex == 0:
+				builder.WriteString("E")
+			case content.enemy:
+				builder.WriteString("e")
+			case content.powerUp != "":
+				builder.WriteString(textPowerUpGlyphs[content.powerUp])
+			case content.snakeIndex < 0:
+				builder.WriteString(textFoodGlyphs[content.food])
+			case content.snakeIndex == 0:
+				builder.WriteString("@")
+			default:
+				builder.WriteString("o")
+			}
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,23 @@
+package render
+
+import (
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game"
+)
+
+//TUI draws into the game view of the gocui interface.
+type TUI struct {
+	gui *gocui.Gui
+}
+
+func NewTUI(gui *gocui.Gui) *TUI {
+	return &TUI{gui}
+}
+
+func (t *TUI) Render(state game.State) error {
+	return game.DrawState(t.gui, state)
+}
+
+func (t *TUI) Close() error {
+	return nil
+}
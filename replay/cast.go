@@ -0,0 +1,62 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/render"
+	"io"
+	"time"
+)
+
+//Header of an asciinema v2 cast
+type castHeader struct {
+	Version int               `json:"version"`
+	Width   int               `json:"width"`
+	Height  int               `json:"height"`
+	Env     map[string]string `json:"env"`
+}
+
+//WriteCast writes the replay as an asciinema v2 cast, drawing the frames with the ANSI renderer.
+func WriteCast(w io.Writer, replay Replay) error {
+	if len(replay.Frames) == 0 {
+		return fmt.Errorf("replay has no frames")
+	}
+	first := replay.Frames[0]
+	header := castHeader{
+		Version: 2,
+		//The board frame adds two columns and the frame and status line three rows
+		Width:  first.Cols*game.DeltaX + 2,
+		Height: first.Rows*game.DeltaY + 3,
+		Env:    map[string]string{"TERM": "xterm-256color"},
+	}
+	if err := writeCastLine(w, header); err != nil {
+		return err
+	}
+
+	//Each frame is the output of the ANSI renderer for that frame, including the screen setup before the first one
+	var output bytes.Buffer
+	ansi := render.NewANSI(&output)
+	elapsed := time.Duration(0)
+	for _, state := range replay.Frames {
+		if err := ansi.Render(state); err != nil {
+			return err
+		}
+		if err := writeCastLine(w, []interface{}{elapsed.Seconds(), "o", output.String()}); err != nil {
+			return err
+		}
+		output.Reset()
+		elapsed += state.TickInterval
+	}
+	return nil
+}
+
+func writeCastLine(w io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
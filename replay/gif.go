@@ -0,0 +1,174 @@
+package replay
+
+import (
+	"fmt"
+	"github.com/awesome-gocui/gocui"
+	"github.com/eiba/snake/game"
+	"github.com/eiba/snake/game/view"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+)
+
+const (
+	//Browsers play frames shorter than 2/100s at a much lower speed
+	minFrameDelay = 2
+	maxGIFColors  = 256
+)
+
+//Colours used for the theme's default foreground and background
+var (
+	defaultForeground = color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+	defaultBackground = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+//WriteGIF draws the replay as an animated GIF with one frame per tick and cells of cellSize pixels,
+//in the colours of the current theme.
+func WriteGIF(w io.Writer, replay Replay, cellSize int) error {
+	if len(replay.Frames) == 0 {
+		return fmt.Errorf("replay has no frames")
+	}
+	palette := newGIFPalette()
+	animation := &gif.GIF{}
+	width, height := 0, 0
+	for _, state := range replay.Frames {
+		delay := int(state.TickInterval.Seconds() * 100)
+		if delay < minFrameDelay {
+			delay = minFrameDelay
+		}
+		frame := gifFrame(state, cellSize, palette)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, delay)
+		if frame.Rect.Dx() > width {
+			width = frame.Rect.Dx()
+		}
+		if frame.Rect.Dy() > height {
+			height = frame.Rect.Dy()
+		}
+	}
+
+	//The palette grows while the frames are drawn, so it is only set once all of them are done.
+	//Sharing it lets the encoder write it once as the global colour table.
+	for _, frame := range animation.Image {
+		frame.Palette = palette.colors
+	}
+	animation.Config = image.Config{ColorModel: palette.colors, Width: width, Height: height}
+	return gif.EncodeAll(w, animation)
+}
+
+//Palette of the colours used by the replay, filled while the frames are drawn.
+//The colours of a theme fit easily into the 256 colours a GIF allows. Should a theme use more, the colours past
+//the 256th are drawn in the closest colour of the palette.
+type gifPalette struct {
+	colors  color.Palette
+	indices map[color.RGBA]uint8
+}
+
+func newGIFPalette() *gifPalette {
+	return &gifPalette{indices: make(map[color.RGBA]uint8)}
+}
+
+func (p *gifPalette) index(c color.RGBA) uint8 {
+	if index, exist := p.indices[c]; exist {
+		return index
+	}
+	if len(p.colors) == maxGIFColors {
+		return uint8(p.colors.Index(c))
+	}
+	index := uint8(len(p.colors))
+	p.colors = append(p.colors, c)
+	p.indices[c] = index
+	return index
+}
+
+func gifFrame(state game.State, cellSize int, palette *gifPalette) *image.Paletted {
+	type styledCell struct {
+		cell  game.Cell
+		style view.CellStyle
+	}
+	var cells []styledCell
+	for _, cell := range state.Walls {
+		cells = append(cells, styledCell{cell, view.WallStyle()})
+	}
+	closed, warned := state.ArenaCells()
+	for _, cell := range closed {
+		cells = append(cells, styledCell{cell, view.WallStyle()})
+	}
+	for _, cell := range warned {
+		cells = append(cells, styledCell{cell, view.WarningStyle()})
+	}
+	for _, p := range state.Portals {
+		cells = append(cells, styledCell{p.Cell, view.PortalStyle()})
+	}
+	for _, f := range state.Foods {
+		cells = append(cells, styledCell{f.Cell, view.FoodStyle(f.Type)})
+	}
+	for _, p := range state.PowerUps {
+		cells = append(cells, styledCell{p.Cell, view.PowerUpStyle(p.Type)})
+	}
+	for _, cell := range state.Hazards {
+		cells = append(cells, styledCell{cell, view.HazardStyle()})
+	}
+	for _, enemy := range state.Enemies {
+		for i := len(enemy) - 1; i >= 0; i-- {
+			cells = append(cells, styledCell{enemy[i], view.EnemyStyle(i)})
+		}
+	}
+	for i := len(state.Snake) - 1; i >= 0; i-- {
+		cells = append(cells, styledCell{state.Snake[i], view.SnakeStyle(i, len(state.Snake))})
+	}
+
+	background := attributeColor(view.BackgroundColor(), defaultBackground)
+	pixels := make([]uint8, state.Cols*cellSize*state.Rows*cellSize)
+	backgroundIndex := palette.index(background)
+	for i := range pixels {
+		pixels[i] = backgroundIndex
+	}
+	frame := &image.Paletted{Pix: pixels, Stride: state.Cols * cellSize, Rect: image.Rect(0, 0, state.Cols*cellSize, state.Rows*cellSize)}
+	for _, c := range cells {
+		fillCell(frame, c.cell, cellSize, 0, palette.index(attributeColor(c.style.Bg, background)))
+		if c.style.Glyph != ' ' {
+			fillCell(frame, c.cell, cellSize, cellSize/4, palette.index(attributeColor(c.style.Fg, defaultForeground)))
+		}
+	}
+	return frame
+}
+
+//Fills the cell, leaving a border of inset pixels, so glyphs are drawn as a smaller square inside the cell.
+func fillCell(frame *image.Paletted, cell game.Cell, cellSize int, inset int, index uint8) {
+	for y := cell.Row*cellSize + inset; y < (cell.Row+1)*cellSize-inset; y++ {
+		for x := cell.Col*cellSize + inset; x < (cell.Col+1)*cellSize-inset; x++ {
+			frame.SetColorIndex(x, y, index)
+		}
+	}
+}
+
+//Translates a gocui colour into RGB. Colours are palette indices offset by one, in both output modes.
+func attributeColor(attribute gocui.Attribute, defaultColor color.RGBA) color.RGBA {
+	if attribute == gocui.ColorDefault {
+		return defaultColor
+	}
+	return paletteColor(int(attribute) - 1)
+}
+
+//Returns the RGB value of a colour in the xterm 256 colour palette.
+func paletteColor(index int) color.RGBA {
+	basic := []color.RGBA{
+		{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+		{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+		{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+		{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+	}
+	switch {
+	case index < 16:
+		return basic[index]
+	case index < 232:
+		levels := []uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+		index -= 16
+		return color.RGBA{levels[index/36], levels[index/6%6], levels[index%6], 0xff}
+	default:
+		gray := uint8(8 + (index-232)*10)
+		return color.RGBA{gray, gray, gray, 0xff}
+	}
+}
@@ -0,0 +1,87 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/eiba/snake/game"
+	"io/ioutil"
+)
+
+//Version of the replay files that are written, replays of any other version are not loaded
+const formatVersion = 1
+
+//Replay is a recorded game: every frame that was shown, in order. Each frame holds the time until the next one.
+type Replay struct {
+	Version int
+	Frames  []game.State
+}
+
+//Recorder collects the frames of a game and saves them as a replay when it is closed.
+//It is a render.Renderer, so it can record next to the renderer that draws the game.
+//A recorder without a path is only saved with Save.
+type Recorder struct {
+	path   string
+	replay Replay
+	//Frames kept at most, the oldest being overwritten first, or 0 to keep every frame
+	maxFrames int
+	//Index of the oldest frame once the frames have wrapped around
+	start int
+}
+
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path, replay: Replay{Version: formatVersion}}
+}
+
+//NewCappedRecorder returns a recorder without a path that only keeps the last maxFrames frames.
+func NewCappedRecorder(maxFrames int) *Recorder {
+	return &Recorder{replay: Replay{Version: formatVersion}, maxFrames: maxFrames}
+}
+
+func (r *Recorder) Render(state game.State) error {
+	if r.maxFrames > 0 && len(r.replay.Frames) == r.maxFrames {
+		r.replay.Frames[r.start] = state
+		r.start = (r.start + 1) % r.maxFrames
+		return nil
+	}
+	r.replay.Frames = append(r.replay.Frames, state)
+	return nil
+}
+
+func (r *Recorder) Close() error {
+	if r.path == "" {
+		return nil
+	}
+	return r.Save(r.path)
+}
+
+//Writes the frames recorded so far to path as a replay, oldest first.
+func (r *Recorder) Save(path string) error {
+	replay := r.replay
+	replay.Frames = append(append([]game.State{}, r.replay.Frames[r.start:]...), r.replay.Frames[:r.start]...)
+	data, err := json.Marshal(replay)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+//Drops the frames recorded so far, e.g. when a new game starts.
+func (r *Recorder) Reset() {
+	r.replay = Replay{Version: formatVersion}
+	r.start = 0
+}
+
+func Load(path string) (Replay, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Replay{}, err
+	}
+	var replay Replay
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return Replay{}, err
+	}
+	if replay.Version != formatVersion {
+		return Replay{}, fmt.Errorf("%v has unknown replay format version %v", path, replay.Version)
+	}
+	return replay, nil
+}
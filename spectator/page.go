@@ -0,0 +1,101 @@
+package spectator
+
+//The spectator page. It draws every state it receives on a canvas and, in control mode, sends arrow key presses back.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>snake</title>
+<style>
+  body { background: #1c1c1c; color: #ddd; font-family: monospace; text-align: center; }
+  canvas { background: #262626; margin-top: 1em; }
+</style>
+</head>
+<body>
+<canvas id="board"></canvas>
+<p id="status">connecting...</p>
+<script>
+const canvas = document.getElementById("board");
+const context = canvas.getContext("2d");
+const status = document.getElementById("status");
+const directions = {ArrowUp: "up", ArrowRight: "right", ArrowDown: "down", ArrowLeft: "left"};
+const foodColors = {regular: "#d70000", golden: "#ffd700", growth: "#00d7ff", poison: "#af00ff"};
+const powerUpColors = {"slow-motion": "#5fafff", ghost: "#bcbcbc", shrink: "#ff8700"};
+let control = false;
+//The page steers the player's snake, or the snake of the bot given as ?snake=2 in the address
+const snake = new URLSearchParams(location.search).get("snake");
+
+function ringClosed(state, col, row, ring) {
+  return col < ring || row < ring || col >= state.Cols - ring || row >= state.Rows - ring;
+}
+
+function draw(state) {
+  const size = Math.max(4, Math.floor(Math.min((window.innerWidth - 40) / state.Cols, (window.innerHeight - 100) / state.Rows)));
+  canvas.width = state.Cols * size;
+  canvas.height = state.Rows * size;
+  context.fillStyle = "#585858";
+  state.Walls.forEach(wall => context.fillRect(wall.Col * size, wall.Row * size, size, size));
+  for (let col = 0; col < state.Cols; col++) {
+    for (let row = 0; row < state.Rows; row++) {
+      if (ringClosed(state, col, row, state.ArenaRing)) {
+        context.fillStyle = "#585858";
+      } else if (state.ArenaWarning && ringClosed(state, col, row, state.ArenaRing + 1)) {
+        context.fillStyle = "#5f0000";
+      } else {
+        continue;
+      }
+      context.fillRect(col * size, row * size, size, size);
+    }
+  }
+  context.strokeStyle = "#ff00ff";
+  context.lineWidth = Math.max(1, size / 6);
+  state.Portals.forEach(portal => {
+    context.beginPath();
+    context.arc((portal.Col + 0.5) * size, (portal.Row + 0.5) * size, size / 3, 0, 2 * Math.PI);
+    context.stroke();
+  });
+  state.Foods.forEach(food => {
+    context.fillStyle = foodColors[food.Type];
+    context.fillRect(food.Col * size, food.Row * size, size, size);
+  });
+  state.PowerUps.forEach(powerUp => {
+    context.fillStyle = powerUpColors[powerUp.Type];
+    context.beginPath();
+    context.arc((powerUp.Col + 0.5) * size, (powerUp.Row + 0.5) * size, size / 2, 0, 2 * Math.PI);
+    context.fill();
+  });
+  context.fillStyle = "#ff5f00";
+  state.Hazards.forEach(hazard => context.fillRect(hazard.Col * size, hazard.Row * size, size, size));
+  state.Enemies.forEach(enemy => enemy.forEach((cell, i) => {
+    context.fillStyle = i === 0 ? "#ff87ff" : "#af5faf";
+    context.fillRect(cell.Col * size + 1, cell.Row * size + 1, size - 2, size - 2);
+  }));
+  state.Snake.forEach((cell, i) => {
+    context.fillStyle = i === 0 ? "#87ff00" : i === state.Snake.length - 1 ? "#005f00" : "#00af00";
+    context.fillRect(cell.Col * size + 1, cell.Row * size + 1, size - 2, size - 2);
+  });
+  status.textContent = "score " + state.Score + " length " + state.Snake.length + " tick " + state.Tick +
+    Object.keys(state.Effects).sort().map(name => " " + name + " " + state.Effects[name]).join("") +
+    (state.RunOver ? " - game over" : "") + (control ? " - arrow keys steer " + (snake ? "bot snake " + snake : "the snake") : " - watching");
+}
+
+const socket = new WebSocket("ws://" + location.host + "/ws");
+socket.onmessage = event => {
+  const message = JSON.parse(event.data);
+  if ("control" in message) {
+    control = message.control;
+    return;
+  }
+  draw(message);
+};
+socket.onclose = () => { status.textContent = "disconnected"; };
+document.addEventListener("keydown", event => {
+  if (control && directions[event.key]) {
+    socket.send((snake ? snake + " " : "") + directions[event.key]);
+    event.preventDefault();
+  }
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,171 @@
+package spectator
+
+import (
+	"encoding/json"
+	"github.com/eiba/snake/game"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//Frames are dropped for spectators that fall this many frames behind
+const clientBufferSize = 16
+
+var directionNames = map[string]game.Direction{
+	"up":    game.Directions.Up,
+	"right": game.Directions.Right,
+	"down":  game.Directions.Down,
+	"left":  game.Directions.Left,
+}
+
+//Server streams the board to browsers over a WebSocket. It is a render.Renderer, so it can be fed
+//the same state as the terminal renderer.
+type Server struct {
+	//Called with the snakes and directions sent by spectators when control mode is enabled, nil otherwise.
+	//Snake 0 is the player's, the others are the enemies steered by the bots with those numbers.
+	control func(snake int, direction game.Direction)
+	mutex   sync.Mutex
+	clients map[chan []byte]bool
+	server  *http.Server
+}
+
+//Starts serving the spectator page on address. Spectators may steer the snakes if control is not nil.
+func Start(address string, control func(snake int, direction game.Direction)) (*Server, error) {
+	s := &Server{control: control, clients: make(map[chan []byte]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.servePage)
+	mux.HandleFunc("/ws", s.serveWebsocket)
+	s.server = &http.Server{Handler: mux}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(page))
+}
+
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	//In control mode a page from another site must not be able to steer the snake through the visitor's browser
+	if s.control != nil && !sameOrigin(r) {
+		http.Error(w, "cross-origin websocket requests are not allowed in control mode", http.StatusForbidden)
+		return
+	}
+	conn, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.close()
+
+	frames := make(chan []byte, clientBufferSize)
+	s.mutex.Lock()
+	s.clients[frames] = true
+	s.mutex.Unlock()
+	defer s.removeClient(frames)
+
+	hello, _ := json.Marshal(map[string]bool{"control": s.control != nil})
+	if err := conn.writeMessage(opcodeText, hello); err != nil {
+		return
+	}
+	go s.readControl(conn)
+
+	for frame := range frames {
+		if err := conn.writeMessage(opcodeText, frame); err != nil {
+			return
+		}
+	}
+}
+
+//Reports whether the request comes from the spectator page itself. Clients that are not browsers send no origin.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return originURL.Host == r.Host
+}
+
+func (s *Server) removeClient(frames chan []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.clients[frames] {
+		delete(s.clients, frames)
+		close(frames)
+	}
+}
+
+//Reads direction messages until the connection closes. Messages are ignored unless control mode is enabled.
+func (s *Server) readControl(conn *websocketConn) {
+	defer conn.close()
+	for {
+		message, err := conn.readMessage()
+		if err != nil {
+			return
+		}
+		snake, direction, valid := parseControl(message)
+		if valid && s.control != nil {
+			s.control(snake, direction)
+		}
+	}
+}
+
+//Parses a control message: a direction for the player's snake, e.g. "left", or the number of a bot and a direction
+//for the snake of that bot, e.g. "2 left".
+func parseControl(message string) (int, game.Direction, bool) {
+	fields := strings.Fields(message)
+	snake := 0
+	if len(fields) == 2 {
+		number, err := strconv.Atoi(fields[0])
+		if err != nil || number < 1 {
+			return 0, 0, false
+		}
+		snake, fields = number, fields[1:]
+	}
+	if len(fields) != 1 {
+		return 0, 0, false
+	}
+	direction, exist := directionNames[fields[0]]
+	return snake, direction, exist
+}
+
+func (s *Server) Render(state game.State) error {
+	frame, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for frames := range s.clients {
+		select {
+		case frames <- frame:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	for frames := range s.clients {
+		delete(s.clients, frames)
+		close(frames)
+	}
+	s.mutex.Unlock()
+	return s.server.Close()
+}
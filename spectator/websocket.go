@@ -0,0 +1,146 @@
+package spectator
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+//A minimal WebSocket (RFC 6455) implementation, enough to push frames to a browser and read key presses back.
+
+const (
+	websocketGUID     = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	opcodeText        = 0x1
+	opcodeClose       = 0x8
+	opcodePing        = 0x9
+	opcodePong        = 0xA
+	maxMessageLength  = 1024
+	finalFragmentFlag = 0x80
+	maskFlag          = 0x80
+)
+
+type websocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	//Held while a message is written, as pongs are written by the reading goroutine next to the frames
+	writeMutex sync.Mutex
+}
+
+func upgrade(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		http.Error(w, "expected a websocket upgrade", http.StatusBadRequest)
+		return nil, errors.New("not a websocket request")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("missing websocket key")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websockets are not supported", http.StatusInternalServerError)
+		return nil, errors.New("response writer can not be hijacked")
+	}
+	conn, readWriter, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &websocketConn{conn: conn, reader: readWriter.Reader}, nil
+}
+
+func (c *websocketConn) writeMessage(opcode byte, payload []byte) error {
+	header := []byte{finalFragmentFlag | opcode}
+	length := len(payload)
+	switch {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_, err := c.conn.Write(append(header, payload...))
+	return err
+}
+
+//Reads the next text message, answering pings on the way. It returns io.EOF when the browser closes the connection.
+//Browsers mask every frame they send, so an unmasked frame is an error.
+func (c *websocketConn) readMessage() (string, error) {
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, header); err != nil {
+			return "", err
+		}
+		opcode := header[0] & 0x0F
+		masked := header[1]&maskFlag != 0
+		length := uint64(header[1] & 0x7F)
+		switch length {
+		case 126:
+			extended := make([]byte, 2)
+			if _, err := io.ReadFull(c.reader, extended); err != nil {
+				return "", err
+			}
+			length = uint64(binary.BigEndian.Uint16(extended))
+		case 127:
+			extended := make([]byte, 8)
+			if _, err := io.ReadFull(c.reader, extended); err != nil {
+				return "", err
+			}
+			length = binary.BigEndian.Uint64(extended)
+		}
+		if length > maxMessageLength {
+			return "", errors.New("websocket message too long")
+		}
+		if !masked {
+			return "", errors.New("unmasked websocket frame from the client")
+		}
+
+		mask := make([]byte, 4)
+		if _, err := io.ReadFull(c.reader, mask); err != nil {
+			return "", err
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.reader, payload); err != nil {
+			return "", err
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+
+		switch opcode {
+		case opcodeClose:
+			return "", io.EOF
+		case opcodePing:
+			if err := c.writeMessage(opcodePong, payload); err != nil {
+				return "", err
+			}
+		case opcodeText:
+			return string(payload), nil
+		}
+	}
+}
+
+func (c *websocketConn) close() error {
+	return c.conn.Close()
+}